@@ -3,12 +3,42 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/sentinel-official/dvpn-node/api/errors"
+	"github.com/sentinel-official/dvpn-node/api/openapi"
+	"github.com/sentinel-official/dvpn-node/api/prices"
 	"github.com/sentinel-official/dvpn-node/api/session"
 	"github.com/sentinel-official/dvpn-node/api/status"
 	"github.com/sentinel-official/dvpn-node/context"
+	"github.com/sentinel-official/dvpn-node/types"
 )
 
+const (
+	Title    = "Sentinel dVPN Node API"
+	Version  = "v1"
+	BasePath = "/" + Version
+)
+
+func Routes() (items []openapi.Route) {
+	items = append(items, errors.Routes()...)
+	items = append(items, prices.Routes()...)
+	items = append(items, session.Routes()...)
+	items = append(items, status.Routes()...)
+
+	return items
+}
+
+func NewDocument() *openapi.Document {
+	return openapi.NewDocument(Title, Version, BasePath, Routes()...)
+}
+
 func RegisterRoutes(ctx *context.Context, r gin.IRouter) {
-	session.RegisterRoutes(ctx, r)
-	status.RegisterRoutes(ctx, r)
+	v1 := r.Group(BasePath, func(c *gin.Context) {
+		c.Set(types.ContextKeyAPIVersion, Version)
+	})
+
+	errors.RegisterRoutes(r, v1)
+	prices.RegisterRoutes(ctx, r, v1)
+	session.RegisterRoutes(ctx, r, v1)
+	status.RegisterRoutes(ctx, r, v1)
+	openapi.RegisterRoutes(NewDocument(), r)
 }
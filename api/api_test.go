@@ -0,0 +1,383 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gin-gonic/gin"
+	hubtypes "github.com/sentinel-official/hub/types"
+
+	"github.com/sentinel-official/dvpn-node/api/openapi"
+	"github.com/sentinel-official/dvpn-node/context"
+	geoiptypes "github.com/sentinel-official/dvpn-node/libs/geoip/types"
+	"github.com/sentinel-official/dvpn-node/lite"
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+type testService struct{}
+
+func (testService) Type() uint64                                         { return 1 }
+func (testService) Info() []byte                                         { return nil }
+func (testService) Init(string) error                                    { return nil }
+func (testService) Start() error                                         { return nil }
+func (testService) Stop() error                                          { return nil }
+func (testService) AddPeer([]byte) ([]byte, error)                       { return nil, nil }
+func (testService) HasPeer([]byte) bool                                  { return false }
+func (testService) RemovePeer([]byte) error                              { return nil }
+func (testService) RekeyPeer([]byte, []byte) ([]byte, error)             { return nil, nil }
+func (testService) Peers() ([]types.Peer, error)                         { return nil, nil }
+func (testService) PeerCount() int                                       { return 0 }
+func (testService) PeerConfig(string, []byte) (*types.PeerConfig, error) { return nil, nil }
+func (testService) Health() types.ServiceHealth                          { return types.ServiceHealth{Running: true} }
+
+func (testService) Ports() []uint16 { return []uint16{51820} }
+
+func (testService) Endpoints() []types.ServiceEndpoint {
+	return []types.ServiceEndpoint{
+		{Protocol: "wireguard", Port: 51820, Transport: "udp"},
+	}
+}
+
+func newTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	services := types.NewServices()
+	if err := services.Add(testService{}); err != nil {
+		panic(err)
+	}
+
+	var (
+		ctx = context.NewContext().
+			WithBandwidth(&hubtypes.Bandwidth{Upload: sdk.NewInt(1), Download: sdk.NewInt(1)}).
+			WithClient(lite.NewDefaultClient().WithFromAddress(sdk.AccAddress(bytes.Repeat([]byte{1}, 20)))).
+			WithConfig(types.NewConfig().WithDefaultValues()).
+			WithLocation(&geoiptypes.GeoIPLocation{}).
+			WithPlans(types.NewPlans()).
+			WithServices(services)
+		router = gin.New()
+	)
+
+	RegisterRoutes(ctx, router)
+	return router
+}
+
+// validateSchema checks the decoded JSON value against the schema, where the
+// properties missing in the schema are errors and the null values are valid.
+func validateSchema(path string, s *openapi.Schema, v interface{}) error {
+	if v == nil {
+		return nil
+	}
+
+	switch s.Type {
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %T", path, v)
+		}
+
+		for key, value := range m {
+			property, ok := s.Properties[key]
+			if !ok {
+				property = s.AdditionalProperties
+			}
+			if property == nil {
+				return fmt.Errorf("%s: property %s is not in the schema", path, key)
+			}
+			if err := validateSchema(path+"."+key, property, value); err != nil {
+				return err
+			}
+		}
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %T", path, v)
+		}
+
+		for i, item := range items {
+			if err := validateSchema(fmt.Sprintf("%s[%d]", path, i), s.Items, item); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: expected a string, got %T", path, v)
+		}
+	case "integer":
+		if f, ok := v.(float64); !ok || f != math.Trunc(f) {
+			return fmt.Errorf("%s: expected an integer, got %v", path, v)
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return fmt.Errorf("%s: expected a number, got %T", path, v)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: expected a boolean, got %T", path, v)
+		}
+	}
+
+	return nil
+}
+
+// sampleOf returns a JSON value with every property of the schema.
+func sampleOf(s *openapi.Schema) interface{} {
+	switch s.Type {
+	case "object":
+		m := make(map[string]interface{})
+		for key, property := range s.Properties {
+			m[key] = sampleOf(property)
+		}
+
+		return m
+	case "array":
+		return []interface{}{sampleOf(s.Items)}
+	case "string":
+		return "AA=="
+	case "integer", "number":
+		return 1
+	case "boolean":
+		return true
+	default:
+		return nil
+	}
+}
+
+func TestOpenAPIDocumentMatchesRoutes(t *testing.T) {
+	var (
+		router   = newTestRouter()
+		expected []string
+		actual   []string
+	)
+
+	for _, route := range router.Routes() {
+		if !strings.HasPrefix(route.Path, BasePath+"/") {
+			continue
+		}
+
+		path := openapi.Path(strings.TrimPrefix(route.Path, BasePath))
+		expected = append(expected, route.Method+" "+path)
+	}
+
+	doc := NewDocument()
+	for path, operations := range doc.Paths {
+		for method, operation := range operations {
+			actual = append(actual, strings.ToUpper(method)+" "+path)
+
+			var params []string
+			for _, param := range operation.Parameters {
+				if param.In == "path" {
+					params = append(params, param.Name)
+				}
+			}
+
+			var names []string
+			for _, match := range regexp.MustCompile(`{([^}]+)}`).FindAllStringSubmatch(path, -1) {
+				names = append(names, match[1])
+			}
+
+			sort.Strings(params)
+			sort.Strings(names)
+			if strings.Join(params, ",") != strings.Join(names, ",") {
+				t.Errorf("path parameters mismatch for %s %s; expected %v, got %v", method, path, names, params)
+			}
+		}
+	}
+
+	sort.Strings(expected)
+	sort.Strings(actual)
+	if strings.Join(expected, "\n") != strings.Join(actual, "\n") {
+		t.Fatalf("routes mismatch; expected %v, got %v", expected, actual)
+	}
+}
+
+func TestOpenAPIDocumentServed(t *testing.T) {
+	var (
+		router = newTestRouter()
+		rec    = httptest.NewRecorder()
+		req    = httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	)
+
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code %d", rec.Code)
+	}
+
+	var doc openapi.Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != openapi.Version {
+		t.Fatalf("unexpected openapi version %s", doc.OpenAPI)
+	}
+	if len(doc.Paths) != len(NewDocument().Paths) {
+		t.Fatalf("unexpected number of paths %d", len(doc.Paths))
+	}
+}
+
+func TestErrorCodesOfRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		code   types.ErrorCode
+		status int
+	}{
+		{
+			name:   "unversioned route",
+			path:   "/accounts/invalid/sessions/1",
+			code:   2,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "versioned route",
+			path:   BasePath + "/accounts/invalid/sessions/1",
+			code:   types.ErrorCodeInvalidRequest,
+			status: types.ErrorCodeInvalidRequest.HTTPStatus(),
+		},
+	}
+
+	router := newTestRouter()
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				rec = httptest.NewRecorder()
+				req = httptest.NewRequest(http.MethodGet, tc.path+"?signature=AA==", nil)
+			)
+
+			router.ServeHTTP(rec, req)
+			if rec.Code != tc.status {
+				t.Fatalf("unexpected status code %d", rec.Code)
+			}
+
+			var res struct {
+				Error *types.Error `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatal(err)
+			}
+			if res.Error == nil || res.Error.Code != tc.code {
+				t.Fatalf("expected error code %d, got %+v", tc.code, res.Error)
+			}
+		})
+	}
+}
+
+func TestOpenAPIRequestSchemas(t *testing.T) {
+	doc := NewDocument()
+	for _, route := range Routes() {
+		if route.Body == nil {
+			continue
+		}
+
+		schema := doc.Paths[openapi.Path(route.Path)][strings.ToLower(route.Method)].RequestBody.Content["application/json"].Schema
+		t.Run(route.OperationID, func(t *testing.T) {
+			buf, err := json.Marshal(sampleOf(schema))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Every property of the schema is bound by the handler.
+			body := reflect.New(reflect.TypeOf(route.Body)).Interface()
+			decoder := json.NewDecoder(bytes.NewReader(buf))
+			decoder.DisallowUnknownFields()
+			if err = decoder.Decode(body); err != nil {
+				t.Fatalf("schema does not match the request body: %s", err)
+			}
+
+			// Every field bound by the handler is in the schema.
+			buf, err = json.Marshal(route.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var v interface{}
+			if err = json.Unmarshal(buf, &v); err != nil {
+				t.Fatal(err)
+			}
+			if err = validateSchema("body", schema, v); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestOpenAPIResponseSchemas(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+	}{
+		{
+			name:   "errors",
+			method: http.MethodGet,
+			path:   "/errors",
+			status: http.StatusOK,
+		},
+		{
+			name:   "status",
+			method: http.MethodGet,
+			path:   "/status",
+			status: http.StatusOK,
+		},
+		{
+			name:   "error response",
+			method: http.MethodGet,
+			path:   "/accounts/invalid/sessions/1",
+			status: types.ErrorCodeInvalidRequest.HTTPStatus(),
+		},
+	}
+
+	var (
+		doc    = NewDocument()
+		router = newTestRouter()
+	)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				rec = httptest.NewRecorder()
+				req = httptest.NewRequest(tc.method, BasePath+tc.path, nil)
+			)
+
+			router.ServeHTTP(rec, req)
+			if rec.Code != tc.status {
+				t.Fatalf("unexpected status code %d; %s", rec.Code, rec.Body)
+			}
+
+			var path string
+			for p := range doc.Paths {
+				if regexp.MustCompile("^" + regexp.MustCompile(`{[^}]+}`).ReplaceAllString(p, "[^/]+") + "$").MatchString(tc.path) {
+					path = p
+				}
+			}
+
+			operation := doc.Paths[path][strings.ToLower(tc.method)]
+			if operation == nil {
+				t.Fatalf("operation for %s %s does not exist", tc.method, tc.path)
+			}
+
+			response, ok := operation.Responses[fmt.Sprintf("%d", tc.status)]
+			if !ok {
+				response = operation.Responses["default"]
+			}
+
+			var v interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+				t.Fatal(err)
+			}
+			if err := validateSchema("response", response.Content["application/json"].Schema, v); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
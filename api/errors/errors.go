@@ -0,0 +1,18 @@
+package errors
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+// Abort responds with the error, using the codes and the HTTP statuses of the
+// unversioned API on its routes, which the existing clients depend on.
+func Abort(c *gin.Context, code types.ErrorCode, err error) {
+	status := code.HTTPStatus()
+	if c.GetString(types.ContextKeyAPIVersion) == "" {
+		code, status = code.Legacy()
+	}
+
+	c.AbortWithStatusJSON(status, types.NewResponseError(code, err))
+}
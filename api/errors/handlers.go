@@ -0,0 +1,15 @@
+package errors
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+func HandlerGetErrors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, types.NewResponseResult(types.ErrorCodes()))
+	}
+}
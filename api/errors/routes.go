@@ -0,0 +1,29 @@
+package errors
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sentinel-official/dvpn-node/api/openapi"
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+func Routes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/errors",
+			OperationID: "getErrors",
+			Summary:     "List the error codes returned by the API",
+			Tags:        []string{"errors"},
+			Result:      []types.ErrorCodeInfo{},
+		},
+	}
+}
+
+func RegisterRoutes(routers ...gin.IRouter) {
+	for _, r := range routers {
+		r.GET("/errors", HandlerGetErrors())
+	}
+}
@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"sync"
+	"time"
+)
+
+type offender struct {
+	failures int
+	failAt   time.Time
+	banUntil time.Time
+}
+
+type banlist struct {
+	mutex     sync.Mutex
+	offenders map[string]*offender
+	threshold int
+	duration  time.Duration
+	cleanupAt time.Time
+}
+
+func newBanlist(threshold int, duration time.Duration) *banlist {
+	return &banlist{
+		offenders: make(map[string]*offender),
+		threshold: threshold,
+		duration:  duration,
+		cleanupAt: time.Now(),
+	}
+}
+
+func (b *banlist) cleanup(now time.Time) {
+	if now.Sub(b.cleanupAt) < limiterCleanupInterval {
+		return
+	}
+
+	for key, o := range b.offenders {
+		if now.After(o.banUntil) && now.Sub(o.failAt) > b.duration {
+			delete(b.offenders, key)
+		}
+	}
+
+	b.cleanupAt = now
+}
+
+func (b *banlist) IsBanned(key string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	o, ok := b.offenders[key]
+	if !ok {
+		return false
+	}
+
+	return time.Now().Before(o.banUntil)
+}
+
+func (b *banlist) Fail(key string) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := time.Now()
+	b.cleanup(now)
+
+	o, ok := b.offenders[key]
+	if !ok {
+		o = &offender{}
+		b.offenders[key] = o
+	}
+	if now.Sub(o.failAt) > b.duration {
+		o.failures = 0
+	}
+
+	o.failures++
+	o.failAt = now
+	if o.failures < b.threshold {
+		return false
+	}
+
+	o.failures = 0
+	o.banUntil = now.Add(b.duration)
+
+	return true
+}
@@ -0,0 +1,106 @@
+package middlewares
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	limiterCleanupInterval = 1 * time.Minute
+	limiterMaxBuckets      = 1 << 16
+)
+
+type bucket struct {
+	tokens   float64
+	updateAt time.Time
+}
+
+type limiter struct {
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	rate      float64
+	burst     float64
+	size      int
+	cleanupAt time.Time
+}
+
+func newLimiter(rate float64, burst int) *limiter {
+	return &limiter{
+		buckets:   make(map[string]*bucket),
+		rate:      rate,
+		burst:     float64(burst),
+		size:      limiterMaxBuckets,
+		cleanupAt: time.Now(),
+	}
+}
+
+func (l *limiter) cleanup(now time.Time, force bool) {
+	if !force && now.Sub(l.cleanupAt) < limiterCleanupInterval {
+		return
+	}
+
+	for key, b := range l.buckets {
+		elapsed := now.Sub(b.updateAt).Seconds()
+		if b.tokens+elapsed*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.cleanupAt = now
+}
+
+// evict removes the least recently updated bucket, which is the closest to
+// being refilled.
+func (l *limiter) evict() {
+	var (
+		key    string
+		oldest *bucket
+	)
+
+	for k, b := range l.buckets {
+		if oldest == nil || b.updateAt.Before(oldest.updateAt) {
+			key, oldest = k, b
+		}
+	}
+
+	delete(l.buckets, key)
+}
+
+func (l *limiter) Allow(key string) bool {
+	return l.allow(key, time.Now())
+}
+
+func (l *limiter) allow(key string, now time.Time) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.cleanup(now, false)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= l.size {
+			l.cleanup(now, true)
+		}
+		if len(l.buckets) >= l.size {
+			l.evict()
+		}
+
+		b = &bucket{
+			tokens:   l.burst,
+			updateAt: now,
+		}
+		l.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.updateAt).Seconds()
+	b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rate)
+	b.updateAt = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
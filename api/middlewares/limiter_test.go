@@ -0,0 +1,100 @@
+package middlewares
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	type request struct {
+		key     string
+		elapsed time.Duration
+		allowed bool
+	}
+
+	tests := []struct {
+		name     string
+		rate     float64
+		burst    int
+		requests []request
+	}{
+		{
+			name:  "burst is exhausted",
+			rate:  1,
+			burst: 2,
+			requests: []request{
+				{"a", 0, true},
+				{"a", 0, true},
+				{"a", 0, false},
+			},
+		},
+		{
+			name:  "tokens are refilled over time",
+			rate:  1,
+			burst: 1,
+			requests: []request{
+				{"a", 0, true},
+				{"a", 500 * time.Millisecond, false},
+				{"a", 500 * time.Millisecond, true},
+			},
+		},
+		{
+			name:  "keys are limited separately",
+			rate:  0.1,
+			burst: 1,
+			requests: []request{
+				{"a", 0, true},
+				{"b", 0, true},
+				{"a", 0, false},
+				{"b", 0, false},
+			},
+		},
+		{
+			name:  "refill is capped at the burst",
+			rate:  1,
+			burst: 1,
+			requests: []request{
+				{"a", 0, true},
+				{"a", 1 * time.Hour, true},
+				{"a", 0, false},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				l   = newLimiter(tc.rate, tc.burst)
+				now = time.Now()
+			)
+
+			for i, req := range tc.requests {
+				now = now.Add(req.elapsed)
+				if allowed := l.allow(req.key, now); allowed != req.allowed {
+					t.Errorf("request %d for %s; expected %t, got %t", i, req.key, req.allowed, allowed)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterEviction(t *testing.T) {
+	var (
+		l   = newLimiter(0.001, 1)
+		now = time.Now()
+	)
+
+	l.size = 2
+	for i, key := range []string{"a", "b", "c"} {
+		if !l.allow(key, now.Add(time.Duration(i)*time.Second)) {
+			t.Fatalf("request for %s not allowed", key)
+		}
+	}
+
+	if len(l.buckets) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(l.buckets))
+	}
+	if _, ok := l.buckets["a"]; ok {
+		t.Fatal("expected the oldest bucket to be evicted")
+	}
+}
@@ -0,0 +1,101 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sentinel-official/dvpn-node/api/errors"
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+const (
+	contextKeyAccountLimiter = "account_limiter"
+)
+
+func MaxBodySize(n int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > n {
+			err := fmt.Errorf("request body size cannot be greater than %d bytes", n)
+			errors.Abort(c, types.ErrorCodeRequestTooLarge, err)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, n)
+		c.Next()
+	}
+}
+
+func RateLimitByIP(rate float64, burst int) gin.HandlerFunc {
+	l := newLimiter(rate, burst)
+	return func(c *gin.Context) {
+		if !l.Allow(c.ClientIP()) {
+			err := fmt.Errorf("rate limit exceeded for ip %s", c.ClientIP())
+			errors.Abort(c, types.ErrorCodeRateLimited, err)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RateLimitByAccount hands the limiter of the accounts over to the handlers,
+// which apply it with AllowAccount once the signature is verified, since the
+// address in the URI is not authenticated before that.
+func RateLimitByAccount(rate float64, burst int) gin.HandlerFunc {
+	l := newLimiter(rate, burst)
+	return func(c *gin.Context) {
+		c.Set(contextKeyAccountLimiter, l)
+		c.Next()
+	}
+}
+
+// AllowAccount reports whether the request of the account is within the rate
+// limit, aborting it otherwise.
+func AllowAccount(c *gin.Context, accAddr string) bool {
+	v, ok := c.Get(contextKeyAccountLimiter)
+	if !ok || v.(*limiter).Allow(accAddr) {
+		return true
+	}
+
+	err := fmt.Errorf("rate limit exceeded for account %s", accAddr)
+	errors.Abort(c, types.ErrorCodeRateLimited, err)
+
+	return false
+}
+
+func MaxConcurrent(n int) gin.HandlerFunc {
+	sem := make(chan struct{}, n)
+	return func(c *gin.Context) {
+		select {
+		case sem <- struct{}{}:
+			defer func() { <-sem }()
+		default:
+			err := fmt.Errorf("reached maximum concurrent requests limit %d", n)
+			errors.Abort(c, types.ErrorCodeTooManyRequests, err)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func BanOnInvalidSignature(threshold int, duration time.Duration) gin.HandlerFunc {
+	b := newBanlist(threshold, duration)
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+		if b.IsBanned(ip) {
+			err := fmt.Errorf("ip %s is banned due to repeated signature failures", ip)
+			errors.Abort(c, types.ErrorCodeBanned, err)
+			return
+		}
+
+		c.Next()
+
+		if c.GetBool(types.ContextKeyInvalidSignature) {
+			b.Fail(ip)
+		}
+	}
+}
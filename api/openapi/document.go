@@ -0,0 +1,64 @@
+package openapi
+
+type (
+	Info struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	}
+	Server struct {
+		URL string `json:"url"`
+	}
+	Schema struct {
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Description          string             `json:"description,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	}
+	Parameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required"`
+		Schema      *Schema `json:"schema"`
+	}
+	MediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+	RequestBody struct {
+		Required bool                  `json:"required"`
+		Content  map[string]*MediaType `json:"content"`
+	}
+	Response struct {
+		Description string                `json:"description"`
+		Content     map[string]*MediaType `json:"content,omitempty"`
+	}
+	Operation struct {
+		OperationID string               `json:"operationId"`
+		Summary     string               `json:"summary,omitempty"`
+		Tags        []string             `json:"tags,omitempty"`
+		Parameters  []*Parameter         `json:"parameters,omitempty"`
+		RequestBody *RequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*Response `json:"responses"`
+	}
+	Document struct {
+		OpenAPI string                           `json:"openapi"`
+		Info    *Info                            `json:"info"`
+		Servers []*Server                        `json:"servers,omitempty"`
+		Paths   map[string]map[string]*Operation `json:"paths"`
+	}
+)
+
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Tags        []string
+	URI         interface{}
+	Query       interface{}
+	Body        interface{}
+	Status      int
+	Result      interface{}
+}
@@ -0,0 +1,232 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+const (
+	Version = "3.0.3"
+)
+
+var (
+	reParam = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+
+	typeDuration = reflect.TypeOf(time.Duration(0))
+	typeTime     = reflect.TypeOf(time.Time{})
+)
+
+func Path(v string) string {
+	return reParam.ReplaceAllString(v, "{$1}")
+}
+
+func tagName(tag string) string {
+	return strings.Split(tag, ",")[0]
+}
+
+func SchemaOf(v interface{}) *Schema {
+	if v == nil {
+		return &Schema{}
+	}
+
+	return schemaOfType(reflect.TypeOf(v))
+}
+
+func schemaOfType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case typeTime:
+		return &Schema{Type: "string", Format: "date-time"}
+	case typeDuration:
+		return &Schema{Type: "integer", Format: "int64", Description: "duration in nanoseconds"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOfType(t.Elem())}
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		addProperties(s, t)
+
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+func addProperties(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag, ok := field.Tag.Lookup("json")
+		if field.Anonymous && !ok {
+			ft := field.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addProperties(s, ft)
+			}
+
+			continue
+		}
+		if !ok || tagName(tag) == "-" {
+			continue
+		}
+
+		name := tagName(tag)
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaOfType(field.Type)
+		if desc := field.Tag.Get("description"); desc != "" {
+			property.Description = desc
+		}
+
+		s.Properties[name] = property
+	}
+}
+
+func parametersOf(v interface{}, in, tagKey string) (items []*Parameter) {
+	if v == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := tagName(field.Tag.Get(tagKey))
+		if name == "" || name == "-" {
+			continue
+		}
+
+		items = append(items,
+			&Parameter{
+				Name:        name,
+				In:          in,
+				Description: field.Tag.Get("description"),
+				Required:    in == "path" || strings.Contains(field.Tag.Get("binding"), "required"),
+				Schema:      schemaOfType(field.Type),
+			},
+		)
+	}
+
+	return items
+}
+
+func envelope(name string, v *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"success": {Type: "boolean"},
+			name:      v,
+		},
+	}
+}
+
+func jsonContent(s *Schema) map[string]*MediaType {
+	return map[string]*MediaType{
+		"application/json": {
+			Schema: s,
+		},
+	}
+}
+
+func NewOperation(route Route) *Operation {
+	operation := &Operation{
+		OperationID: route.OperationID,
+		Summary:     route.Summary,
+		Tags:        route.Tags,
+		Responses: map[string]*Response{
+			"default": {
+				Description: "Error response",
+				Content:     jsonContent(envelope("error", SchemaOf(types.Error{}))),
+			},
+		},
+	}
+
+	operation.Parameters = append(operation.Parameters, parametersOf(route.URI, "path", "uri")...)
+	operation.Parameters = append(operation.Parameters, parametersOf(route.Query, "query", "form")...)
+
+	if route.Body != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(SchemaOf(route.Body)),
+		}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	operation.Responses[fmt.Sprintf("%d", status)] = &Response{
+		Description: http.StatusText(status),
+		Content:     jsonContent(envelope("result", SchemaOf(route.Result))),
+	}
+
+	return operation
+}
+
+func NewDocument(title, version, basePath string, routes ...Route) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info: &Info{
+			Title:   title,
+			Version: version,
+		},
+		Servers: []*Server{
+			{
+				URL: basePath,
+			},
+		},
+		Paths: make(map[string]map[string]*Operation),
+	}
+
+	for _, route := range routes {
+		path := Path(route.Path)
+		if _, ok := doc.Paths[path]; !ok {
+			doc.Paths[path] = make(map[string]*Operation)
+		}
+
+		doc.Paths[path][strings.ToLower(route.Method)] = NewOperation(route)
+	}
+
+	return doc
+}
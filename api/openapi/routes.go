@@ -0,0 +1,9 @@
+package openapi
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(doc *Document, r gin.IRouter) {
+	r.GET("/openapi.json", HandlerGetDocument(doc))
+}
@@ -0,0 +1,83 @@
+package prices
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sentinel-official/dvpn-node/api/errors"
+	"github.com/sentinel-official/dvpn-node/context"
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+const (
+	maxTimestampSkew = 5 * time.Minute
+)
+
+func HandlerUpdatePrices(ctx *context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := NewRequestUpdatePrices(c)
+		if err != nil {
+			errors.Abort(c, types.ErrorCodeInvalidRequest, err)
+			return
+		}
+
+		// The timestamp bounds the time a captured request can be replayed in.
+		if skew := time.Since(time.Unix(req.Body.Timestamp, 0)); skew > maxTimestampSkew || skew < -maxTimestampSkew {
+			err = fmt.Errorf("timestamp %d is not within %s of the node time", req.Body.Timestamp, maxTimestampSkew)
+			errors.Abort(c, types.ErrorCodeInvalidRequest, err)
+			return
+		}
+
+		info, err := ctx.Client().Keyring().Key(ctx.Client().FromName())
+		if err != nil {
+			errors.Abort(c, types.ErrorCodePubKeyNotFound, err)
+			return
+		}
+
+		msg := SignBytes(req.Body.Timestamp, req.Body.GigabytePrices, req.Body.HourlyPrices)
+		if ok := info.GetPubKey().VerifySignature(msg, req.Signature); !ok {
+			c.Set(types.ContextKeyInvalidSignature, true)
+			err = fmt.Errorf("invalid signature %s", req.Signature)
+			errors.Abort(c, types.ErrorCodeInvalidSignature, err)
+			return
+		}
+
+		params, err := ctx.Client().QueryNodeParams()
+		if err != nil {
+			errors.Abort(c, types.ErrorCodeParamsQueryFailed, err)
+			return
+		}
+		if err = types.ValidatePrices(req.GigabytePrices, params.MinGigabytePrices, params.MaxGigabytePrices); err != nil {
+			err = fmt.Errorf("invalid gigabyte_prices: %w", err)
+			errors.Abort(c, types.ErrorCodeInvalidPrices, err)
+			return
+		}
+		if err = types.ValidatePrices(req.HourlyPrices, params.MinHourlyPrices, params.MaxHourlyPrices); err != nil {
+			err = fmt.Errorf("invalid hourly_prices: %w", err)
+			errors.Abort(c, types.ErrorCodeInvalidPrices, err)
+			return
+		}
+		updated, err := ctx.UpdatePrices(req.GigabytePrices, req.HourlyPrices)
+		if err != nil {
+			code := types.ErrorCodeUpdatePricesFailed
+			if stderrors.Is(err, context.ErrPricesCleared) {
+				code = types.ErrorCodeInvalidPrices
+			}
+
+			errors.Abort(c, code, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(
+			&ResponseUpdatePrices{
+				GigabytePrices: ctx.GigabytePrices().String(),
+				HourlyPrices:   ctx.HourlyPrices().String(),
+				Updated:        updated,
+			},
+		))
+	}
+}
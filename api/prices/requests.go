@@ -0,0 +1,49 @@
+package prices
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/gin-gonic/gin"
+)
+
+type RequestUpdatePrices struct {
+	GigabytePrices sdk.Coins
+	HourlyPrices   sdk.Coins
+	Signature      []byte
+
+	Body struct {
+		GigabytePrices string `json:"gigabyte_prices" description:"Per gigabyte prices, e.g. 1000udvpn"`
+		HourlyPrices   string `json:"hourly_prices" description:"Per hour prices, e.g. 100udvpn"`
+		Timestamp      int64  `json:"timestamp" binding:"required" description:"Unix time of the signature in seconds"`
+		Signature      string `json:"signature" binding:"required" description:"Base64 encoded signature of the operator over the timestamp and the prices"`
+	}
+}
+
+func NewRequestUpdatePrices(c *gin.Context) (req *RequestUpdatePrices, err error) {
+	req = &RequestUpdatePrices{}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+
+	req.GigabytePrices, err = sdk.ParseCoinsNormalized(req.Body.GigabytePrices)
+	if err != nil {
+		return nil, err
+	}
+	req.HourlyPrices, err = sdk.ParseCoinsNormalized(req.Body.HourlyPrices)
+	if err != nil {
+		return nil, err
+	}
+	req.Signature, err = base64.StdEncoding.DecodeString(req.Body.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// SignBytes returns the message the operator signs to update the prices.
+func SignBytes(timestamp int64, gigabytePrices, hourlyPrices string) []byte {
+	return []byte(fmt.Sprintf("%d:%s:%s", timestamp, gigabytePrices, hourlyPrices))
+}
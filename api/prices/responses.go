@@ -0,0 +1,9 @@
+package prices
+
+type (
+	ResponseUpdatePrices struct {
+		GigabytePrices string `json:"gigabyte_prices"`
+		HourlyPrices   string `json:"hourly_prices"`
+		Updated        bool   `json:"updated" description:"Whether the prices differed and were broadcast to the chain"`
+	}
+)
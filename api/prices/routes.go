@@ -0,0 +1,38 @@
+package prices
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sentinel-official/dvpn-node/api/middlewares"
+	"github.com/sentinel-official/dvpn-node/api/openapi"
+	"github.com/sentinel-official/dvpn-node/context"
+)
+
+func Routes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method:      http.MethodPut,
+			Path:        "/prices",
+			OperationID: "updatePrices",
+			Summary:     "Update the prices of the node",
+			Tags:        []string{"prices"},
+			Body:        RequestUpdatePrices{}.Body,
+			Result:      ResponseUpdatePrices{},
+		},
+	}
+}
+
+func RegisterRoutes(ctx *context.Context, routers ...gin.IRouter) {
+	var (
+		config    = ctx.Config().API
+		ban       = middlewares.BanOnInvalidSignature(config.BanThreshold, config.BanDuration)
+		ipLimit   = middlewares.RateLimitByIP(config.IPRateLimit, config.IPRateBurst)
+		bodyLimit = middlewares.MaxBodySize(config.MaxBodySize)
+	)
+
+	for _, r := range routers {
+		r.PUT("/prices", ban, ipLimit, bodyLimit, HandlerUpdatePrices(ctx))
+	}
+}
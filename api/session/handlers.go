@@ -1,49 +1,81 @@
 package session
 
 import (
+	"encoding/base64"
 	"fmt"
 	"math"
 	"net/http"
+	"strings"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/gin-gonic/gin"
 	hubtypes "github.com/sentinel-official/hub/types"
 	subscriptiontypes "github.com/sentinel-official/hub/x/subscription/types"
 
+	"github.com/sentinel-official/dvpn-node/api/errors"
+	"github.com/sentinel-official/dvpn-node/api/middlewares"
 	"github.com/sentinel-official/dvpn-node/context"
 	"github.com/sentinel-official/dvpn-node/types"
 )
 
 func HandlerAddSession(ctx *context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if ctx.Service().PeerCount() >= ctx.Config().QOS.MaxPeers {
+		if ctx.Services().PeerCount() >= ctx.Config().QOS.MaxPeers {
 			err := fmt.Errorf("reached maximum peers limit %d", ctx.Config().QOS.MaxPeers)
-			c.JSON(http.StatusBadRequest, types.NewResponseError(1, err))
+			errors.Abort(c, types.ErrorCodeMaxPeersReached, err)
 			return
 		}
 
 		req, err := NewRequestAddSession(c)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, types.NewResponseError(2, err))
+			errors.Abort(c, types.ErrorCodeInvalidRequest, err)
 			return
 		}
 
-		item := types.Session{}
+		service := ctx.Services().Default()
+		if req.Body.Type != 0 {
+			service, err = ctx.Service(req.Body.Type)
+			if err != nil {
+				errors.Abort(c, types.ErrorCodeServiceNotFound, err)
+				return
+			}
+		}
+		if !service.Health().Running {
+			err = fmt.Errorf("service type %d is not running", service.Type())
+			errors.Abort(c, types.ErrorCodeServiceUnavailable, err)
+			return
+		}
+		if v := ctx.Config().QOS.MaxPeersPerService; v > 0 && service.PeerCount() >= v {
+			err = fmt.Errorf("reached maximum peers limit %d for service type %d", v, service.Type())
+			errors.Abort(c, types.ErrorCodeMaxPeersReached, err)
+			return
+		}
+
+		existing := types.Session{}
 		ctx.Database().Model(
 			&types.Session{},
 		).Where(
 			&types.Session{
 				ID: req.URI.ID,
 			},
-		).First(&item)
+		).First(&existing)
 
-		if item.ID != 0 {
-			err = fmt.Errorf("peer for session %d already exist", req.URI.ID)
-			c.JSON(http.StatusBadRequest, types.NewResponseError(3, err))
-			return
+		// A session whose peer has been removed, for being idle or evicted,
+		// can connect again.
+		if existing.ID != 0 {
+			ok, err := ctx.HasPeer(existing.Type, existing.Key)
+			if err != nil {
+				errors.Abort(c, types.ErrorCodePeersQueryFailed, err)
+				return
+			}
+			if ok {
+				err = fmt.Errorf("peer for session %d already exist", req.URI.ID)
+				errors.Abort(c, types.ErrorCodeSessionAlreadyExists, err)
+				return
+			}
 		}
 
-		item = types.Session{}
+		item := types.Session{}
 		ctx.Database().Model(
 			&types.Session{},
 		).Where(
@@ -52,25 +84,25 @@ func HandlerAddSession(ctx *context.Context) gin.HandlerFunc {
 			},
 		).First(&item)
 
-		if item.ID != 0 {
+		if item.ID != 0 && item.ID != existing.ID {
 			err = fmt.Errorf("key %s for service already exist", req.Body.Key)
-			c.JSON(http.StatusBadRequest, types.NewResponseError(3, err))
+			errors.Abort(c, types.ErrorCodeKeyAlreadyExists, err)
 			return
 		}
 
 		account, err := ctx.Client().QueryAccount(req.AccAddress)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(4, err))
+			errors.Abort(c, types.ErrorCodeAccountQueryFailed, err)
 			return
 		}
 		if account == nil {
 			err = fmt.Errorf("account %s does not exist", req.AccAddress)
-			c.JSON(http.StatusNotFound, types.NewResponseError(4, err))
+			errors.Abort(c, types.ErrorCodeAccountNotFound, err)
 			return
 		}
 		if account.GetPubKey() == nil {
 			err = fmt.Errorf("public key for account %s does not exist", req.AccAddress)
-			c.JSON(http.StatusNotFound, types.NewResponseError(4, err))
+			errors.Abort(c, types.ErrorCodePubKeyNotFound, err)
 			return
 		}
 
@@ -80,45 +112,49 @@ func HandlerAddSession(ctx *context.Context) gin.HandlerFunc {
 		)
 
 		if ok := pubKey.VerifySignature(msg, req.Signature); !ok {
+			c.Set(types.ContextKeyInvalidSignature, true)
 			err = fmt.Errorf("invalid signature %s", req.Signature)
-			c.JSON(http.StatusBadRequest, types.NewResponseError(4, err))
+			errors.Abort(c, types.ErrorCodeInvalidSignature, err)
+			return
+		}
+		if !middlewares.AllowAccount(c, req.URI.AccAddress) {
 			return
 		}
 
 		session, err := ctx.Client().QuerySession(req.URI.ID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(5, err))
+			errors.Abort(c, types.ErrorCodeSessionQueryFailed, err)
 			return
 		}
 		if session == nil {
 			err = fmt.Errorf("session %d does not exist", req.URI.ID)
-			c.JSON(http.StatusNotFound, types.NewResponseError(5, err))
+			errors.Abort(c, types.ErrorCodeSessionNotFound, err)
 			return
 		}
 		if !session.Status.Equal(hubtypes.StatusActive) {
 			err = fmt.Errorf("invalid status %s for session %d", session.Status, session.ID)
-			c.JSON(http.StatusNotFound, types.NewResponseError(5, err))
+			errors.Abort(c, types.ErrorCodeSessionInactive, err)
 			return
 		}
 		if session.Address != req.URI.AccAddress {
 			err = fmt.Errorf("account address mismatch; expected %s, got %s", req.URI.AccAddress, session.Address)
-			c.JSON(http.StatusBadRequest, types.NewResponseError(5, err))
+			errors.Abort(c, types.ErrorCodeSessionAddressMismatch, err)
 			return
 		}
 
 		subscription, err := ctx.Client().QuerySubscription(session.SubscriptionID)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(6, err))
+			errors.Abort(c, types.ErrorCodeSubscriptionQueryFailed, err)
 			return
 		}
 		if subscription == nil {
 			err = fmt.Errorf("subscription %d does not exist", session.SubscriptionID)
-			c.JSON(http.StatusNotFound, types.NewResponseError(6, err))
+			errors.Abort(c, types.ErrorCodeSubscriptionNotFound, err)
 			return
 		}
 		if !subscription.GetStatus().Equal(hubtypes.StatusActive) {
 			err = fmt.Errorf("invalid status %s for subscription %d", subscription.GetStatus(), subscription.GetID())
-			c.JSON(http.StatusBadRequest, types.NewResponseError(6, err))
+			errors.Abort(c, types.ErrorCodeSubscriptionInactive, err)
 			return
 		}
 
@@ -126,35 +162,36 @@ func HandlerAddSession(ctx *context.Context) gin.HandlerFunc {
 		case *subscriptiontypes.NodeSubscription:
 			if s.NodeAddress != ctx.Address().String() {
 				err = fmt.Errorf("node address mismatch; expected %s, got %s", ctx.Address(), s.NodeAddress)
-				c.JSON(http.StatusBadRequest, types.NewResponseError(7, err))
+				errors.Abort(c, types.ErrorCodeNodeAddressMismatch, err)
 				return
 			}
 		case *subscriptiontypes.PlanSubscription:
-			exists, err := ctx.Client().HasNodeForPlan(s.PlanID, ctx.Address())
+			exists, err := ctx.HasNodeForPlan(s.PlanID)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, types.NewResponseError(7, err))
+				errors.Abort(c, types.ErrorCodePlanQueryFailed, err)
 				return
 			}
 			if !exists {
 				err = fmt.Errorf("node %s does not exist for plan %d", ctx.Address(), s.PlanID)
-				c.JSON(http.StatusBadRequest, types.NewResponseError(7, err))
+				errors.Abort(c, types.ErrorCodeNodeNotInPlan, err)
 				return
 			}
 		default:
 			err = fmt.Errorf("invalid type %T for subscription %d", s, subscription.GetID())
-			c.JSON(http.StatusBadRequest, types.NewResponseError(7, err))
+			errors.Abort(c, types.ErrorCodeSubscriptionInvalidType, err)
 			return
 		}
 
 		var (
 			checkAllocation       = true
 			remainingBytes  int64 = 0
+			localBytes      int64 = 0
 		)
 
 		if s, ok := subscription.(*subscriptiontypes.NodeSubscription); ok {
 			if req.URI.AccAddress != s.Address {
 				err = fmt.Errorf("account address mismatch; expected %s, got %s", req.URI.AccAddress, s.Address)
-				c.JSON(http.StatusBadRequest, types.NewResponseError(8, err))
+				errors.Abort(c, types.ErrorCodeSubscriptionAddressMismatch, err)
 				return
 			}
 			if s.Hours != 0 {
@@ -165,12 +202,12 @@ func HandlerAddSession(ctx *context.Context) gin.HandlerFunc {
 		if checkAllocation {
 			alloc, err := ctx.Client().QueryAllocation(subscription.GetID(), req.AccAddress)
 			if err != nil {
-				c.JSON(http.StatusInternalServerError, types.NewResponseError(8, err))
+				errors.Abort(c, types.ErrorCodeAllocationQueryFailed, err)
 				return
 			}
 			if alloc == nil {
 				err = fmt.Errorf("allocation %d/%s does not exist", subscription.GetID(), req.AccAddress)
-				c.JSON(http.StatusNotFound, types.NewResponseError(8, err))
+				errors.Abort(c, types.ErrorCodeAllocationNotFound, err)
 				return
 			}
 
@@ -185,13 +222,14 @@ func HandlerAddSession(ctx *context.Context) gin.HandlerFunc {
 			).Find(&items)
 
 			for i := 0; i < len(items); i++ {
+				localBytes += items[i].Download + items[i].Upload
 				utilisedBytes := sdk.NewInt(items[i].Download + items[i].Upload)
 				alloc.UtilisedBytes = alloc.UtilisedBytes.Add(utilisedBytes)
 			}
 
 			if alloc.UtilisedBytes.GTE(alloc.GrantedBytes) {
 				err = fmt.Errorf("invalid allocation; granted bytes %s, utilised bytes %s", alloc.GrantedBytes, alloc.UtilisedBytes)
-				c.JSON(http.StatusBadRequest, types.NewResponseError(8, err))
+				errors.Abort(c, types.ErrorCodeAllocationExceeded, err)
 				return
 			}
 
@@ -211,36 +249,389 @@ func HandlerAddSession(ctx *context.Context) gin.HandlerFunc {
 				Subscription: subscription.GetID(),
 				Address:      req.URI.AccAddress,
 			},
-		).Find(&items)
+		).Order("created_at").Find(&items)
 
+		var devices []types.Session
 		for i := 0; i < len(items); i++ {
-			if err = ctx.RemovePeerIfExists(items[i].Key); err != nil {
-				c.JSON(http.StatusInternalServerError, types.NewResponseError(9, err))
+			ok, err := ctx.HasPeer(items[i].Type, items[i].Key)
+			if err != nil {
+				errors.Abort(c, types.ErrorCodePeersQueryFailed, err)
 				return
 			}
+			if ok {
+				devices = append(devices, items[i])
+			}
 		}
 
-		result, err := ctx.Service().AddPeer(req.Key)
+		result, err := service.AddPeer(req.Key)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, types.NewResponseError(10, err))
+			errors.Abort(c, types.ErrorCodeAddPeerFailed, err)
 			return
 		}
-		ctx.Log().Info("Added a new peer", "key", req.Body.Key, "count", ctx.Service().PeerCount())
+		ctx.Log().Info("Added a new peer", "type", service.Type(), "id", req.URI.ID, "count", service.PeerCount())
+
+		// The configuration is built before the session is saved, removing
+		// the peer when it fails so the client can try again.
+		var config *types.PeerConfig
+		if c.GetString(types.ContextKeyAPIVersion) != "" || strings.Contains(c.GetHeader("Accept"), types.ContentTypeV1) {
+			config, err = service.PeerConfig(ctx.RemoteHost(), req.Key)
+			if err != nil {
+				if err := service.RemovePeer(req.Key); err != nil {
+					ctx.Log().Error("failed to remove the peer", "error", err, "id", req.URI.ID)
+				}
+
+				errors.Abort(c, types.ErrorCodePeerConfigFailed, err)
+				return
+			}
+		}
+
+		// The oldest peers of the account are evicted to make room for the
+		// new one only once it has been added, so a failure leaves the
+		// devices in use connected.
+		for i := 0; len(devices)-i >= ctx.Config().QOS.MaxDevicesPerAccount; i++ {
+			ctx.Log().Info("Evicting the oldest device", "id", devices[i].ID)
+			if err = ctx.RemovePeerIfExists(devices[i].Type, devices[i].Key); err != nil {
+				if err := service.RemovePeer(req.Key); err != nil {
+					ctx.Log().Error("failed to remove the peer", "error", err, "id", req.URI.ID)
+				}
+
+				errors.Abort(c, types.ErrorCodeRemovePeerFailed, err)
+				return
+			}
+		}
+
+		if existing.ID != 0 {
+			// The counters of the new peer start from zero, so the usage
+			// recorded so far becomes the base.
+			ctx.Database().Model(
+				&types.Session{},
+			).Where(
+				&types.Session{
+					ID: existing.ID,
+				},
+			).Updates(
+				map[string]interface{}{
+					"key":           req.Body.Key,
+					"type":          service.Type(),
+					"available":     remainingBytes,
+					"utilised":      localBytes,
+					"base_upload":   existing.Upload,
+					"base_download": existing.Download,
+				},
+			)
+		} else {
+			ctx.Database().Model(
+				&types.Session{},
+			).Create(
+				&types.Session{
+					ID:           req.URI.ID,
+					Subscription: subscription.GetID(),
+					Key:          req.Body.Key,
+					Address:      req.URI.AccAddress,
+					Type:         service.Type(),
+					Available:    remainingBytes,
+					Utilised:     localBytes,
+				},
+			)
+		}
+
+		if config != nil {
+			c.JSON(http.StatusCreated, types.NewResponseResult(config))
+			return
+		}
+
+		result = append(result, ctx.IPv4Address()...)
+		result = append(result, service.Info()...)
+		c.JSON(http.StatusCreated, types.NewResponseResult(result))
+	}
+}
 
+func HandlerRekeySession(ctx *context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := NewRequestRekeySession(c)
+		if err != nil {
+			errors.Abort(c, types.ErrorCodeInvalidRequest, err)
+			return
+		}
+
+		account, err := ctx.Client().QueryAccount(req.AccAddress)
+		if err != nil {
+			errors.Abort(c, types.ErrorCodeAccountQueryFailed, err)
+			return
+		}
+		if account == nil {
+			err = fmt.Errorf("account %s does not exist", req.AccAddress)
+			errors.Abort(c, types.ErrorCodeAccountNotFound, err)
+			return
+		}
+		if account.GetPubKey() == nil {
+			err = fmt.Errorf("public key for account %s does not exist", req.AccAddress)
+			errors.Abort(c, types.ErrorCodePubKeyNotFound, err)
+			return
+		}
+
+		// The new key is part of the signed message, so a signature of the
+		// session ID alone cannot be replayed to take the session over.
+		var (
+			pubKey = account.GetPubKey()
+			msg    = append(sdk.Uint64ToBigEndian(req.URI.ID), req.Key...)
+		)
+
+		if ok := pubKey.VerifySignature(msg, req.Signature); !ok {
+			c.Set(types.ContextKeyInvalidSignature, true)
+			err = fmt.Errorf("invalid signature %s", req.Body.Signature)
+			errors.Abort(c, types.ErrorCodeInvalidSignature, err)
+			return
+		}
+		if !middlewares.AllowAccount(c, req.URI.AccAddress) {
+			return
+		}
+
+		item := types.Session{}
 		ctx.Database().Model(
 			&types.Session{},
-		).Create(
+		).Where(
 			&types.Session{
-				ID:           req.URI.ID,
-				Subscription: subscription.GetID(),
-				Key:          req.Body.Key,
-				Address:      req.URI.AccAddress,
-				Available:    remainingBytes,
+				ID:      req.URI.ID,
+				Address: req.URI.AccAddress,
+			},
+		).First(&item)
+
+		if item.ID == 0 {
+			err = fmt.Errorf("peer for session %d does not exist", req.URI.ID)
+			errors.Abort(c, types.ErrorCodePeerNotFound, err)
+			return
+		}
+
+		other := types.Session{}
+		ctx.Database().Model(
+			&types.Session{},
+		).Where(
+			&types.Session{
+				Key: req.Body.Key,
+			},
+		).First(&other)
+
+		if other.ID != 0 {
+			err = fmt.Errorf("key %s for service already exist", req.Body.Key)
+			errors.Abort(c, types.ErrorCodeKeyAlreadyExists, err)
+			return
+		}
+
+		session, err := ctx.Client().QuerySession(req.URI.ID)
+		if err != nil {
+			errors.Abort(c, types.ErrorCodeSessionQueryFailed, err)
+			return
+		}
+		if session == nil {
+			err = fmt.Errorf("session %d does not exist", req.URI.ID)
+			errors.Abort(c, types.ErrorCodeSessionNotFound, err)
+			return
+		}
+		if !session.Status.Equal(hubtypes.StatusActive) {
+			err = fmt.Errorf("invalid status %s for session %d", session.Status, session.ID)
+			errors.Abort(c, types.ErrorCodeSessionInactive, err)
+			return
+		}
+
+		service, err := ctx.Service(item.Type)
+		if err != nil {
+			errors.Abort(c, types.ErrorCodeServiceNotFound, err)
+			return
+		}
+		if !service.Health().Running {
+			err = fmt.Errorf("service type %d is not running", service.Type())
+			errors.Abort(c, types.ErrorCodeServiceUnavailable, err)
+			return
+		}
+
+		key, err := base64.StdEncoding.DecodeString(item.Key)
+		if err != nil {
+			errors.Abort(c, types.ErrorCodeRekeyPeerFailed, err)
+			return
+		}
+		if !service.HasPeer(key) {
+			err = fmt.Errorf("peer for session %d does not exist", req.URI.ID)
+			errors.Abort(c, types.ErrorCodePeerNotFound, err)
+			return
+		}
+
+		peers, err := service.Peers()
+		if err != nil {
+			errors.Abort(c, types.ErrorCodePeersQueryFailed, err)
+			return
+		}
+
+		// The usage of the current key becomes the base of the new one, which
+		// the service counts from zero.
+		upload, download := item.Upload, item.Download
+		for i := 0; i < len(peers); i++ {
+			if peers[i].Key == item.Key {
+				upload = item.BaseUpload + peers[i].Upload
+				download = item.BaseDownload + peers[i].Download
+				break
+			}
+		}
+
+		// The row is updated first, so a concurrent request for the same
+		// session or key fails here, and restored when the service fails.
+		res := ctx.Database().Model(
+			&types.Session{},
+		).Where(
+			&types.Session{
+				ID:  item.ID,
+				Key: item.Key,
+			},
+		).Updates(
+			map[string]interface{}{
+				"key":           req.Body.Key,
+				"upload":        upload,
+				"download":      download,
+				"base_upload":   upload,
+				"base_download": download,
 			},
 		)
 
+		if res.Error != nil {
+			errors.Abort(c, types.ErrorCodeRekeyPeerFailed, res.Error)
+			return
+		}
+		if res.RowsAffected == 0 {
+			err = fmt.Errorf("peer for session %d has been modified", item.ID)
+			errors.Abort(c, types.ErrorCodeRekeyPeerFailed, err)
+			return
+		}
+
+		result, err := service.RekeyPeer(key, req.Key)
+		if err != nil {
+			ctx.Database().Model(
+				&types.Session{},
+			).Where(
+				&types.Session{
+					ID:  item.ID,
+					Key: req.Body.Key,
+				},
+			).Updates(
+				map[string]interface{}{
+					"key":           item.Key,
+					"upload":        item.Upload,
+					"download":      item.Download,
+					"base_upload":   item.BaseUpload,
+					"base_download": item.BaseDownload,
+				},
+			)
+
+			errors.Abort(c, types.ErrorCodeRekeyPeerFailed, err)
+			return
+		}
+		ctx.Log().Info("Replaced the peer key", "type", service.Type(), "id", item.ID)
+
+		if c.GetString(types.ContextKeyAPIVersion) != "" || strings.Contains(c.GetHeader("Accept"), types.ContentTypeV1) {
+			config, err := service.PeerConfig(ctx.RemoteHost(), req.Key)
+			if err != nil {
+				errors.Abort(c, types.ErrorCodePeerConfigFailed, err)
+				return
+			}
+
+			c.JSON(http.StatusOK, types.NewResponseResult(config))
+			return
+		}
+
 		result = append(result, ctx.IPv4Address()...)
-		result = append(result, ctx.Service().Info()...)
-		c.JSON(http.StatusCreated, types.NewResponseResult(result))
+		result = append(result, service.Info()...)
+		c.JSON(http.StatusOK, types.NewResponseResult(result))
+	}
+}
+
+func HandlerGetSession(ctx *context.Context) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req, err := NewRequestGetSession(c)
+		if err != nil {
+			errors.Abort(c, types.ErrorCodeInvalidRequest, err)
+			return
+		}
+
+		account, err := ctx.Client().QueryAccount(req.AccAddress)
+		if err != nil {
+			errors.Abort(c, types.ErrorCodeAccountQueryFailed, err)
+			return
+		}
+		if account == nil {
+			err = fmt.Errorf("account %s does not exist", req.AccAddress)
+			errors.Abort(c, types.ErrorCodeAccountNotFound, err)
+			return
+		}
+		if account.GetPubKey() == nil {
+			err = fmt.Errorf("public key for account %s does not exist", req.AccAddress)
+			errors.Abort(c, types.ErrorCodePubKeyNotFound, err)
+			return
+		}
+
+		var (
+			pubKey = account.GetPubKey()
+			msg    = sdk.Uint64ToBigEndian(req.URI.ID)
+		)
+
+		if ok := pubKey.VerifySignature(msg, req.Signature); !ok {
+			c.Set(types.ContextKeyInvalidSignature, true)
+			err = fmt.Errorf("invalid signature %s", req.Query.Signature)
+			errors.Abort(c, types.ErrorCodeInvalidSignature, err)
+			return
+		}
+
+		// The sessions of the other accounts are reported as missing, so the
+		// existence of a session is not revealed to the others.
+		item := types.Session{}
+		ctx.Database().Model(
+			&types.Session{},
+		).Where(
+			&types.Session{
+				ID:      req.URI.ID,
+				Address: req.URI.AccAddress,
+			},
+		).First(&item)
+
+		if item.ID == 0 {
+			err = fmt.Errorf("peer for session %d does not exist", req.URI.ID)
+			errors.Abort(c, types.ErrorCodePeerNotFound, err)
+			return
+		}
+
+		service, err := ctx.Service(item.Type)
+		if err != nil {
+			errors.Abort(c, types.ErrorCodeServiceNotFound, err)
+			return
+		}
+
+		peers, err := service.Peers()
+		if err != nil {
+			errors.Abort(c, types.ErrorCodePeersQueryFailed, err)
+			return
+		}
+
+		peer := &Peer{}
+		for i := 0; i < len(peers); i++ {
+			if peers[i].Key == item.Key {
+				peer.Connected = true
+				peer.Download = peers[i].Download
+				peer.Upload = peers[i].Upload
+				break
+			}
+		}
+
+		res := &ResponseGetSession{
+			ID:           item.ID,
+			Subscription: item.Subscription,
+			Address:      item.Address,
+			Type:         service.Type(),
+			Available:    item.Available,
+			Download:     item.Download,
+			Upload:       item.Upload,
+			Peer:         peer,
+			CreatedAt:    item.CreatedAt,
+			UpdatedAt:    item.UpdatedAt,
+		}
+
+		c.JSON(http.StatusOK, types.NewResponseResult(res))
 	}
 }
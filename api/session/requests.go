@@ -13,12 +13,13 @@ type RequestAddSession struct {
 	Signature  []byte
 
 	URI struct {
-		AccAddress string `uri:"acc_address"`
-		ID         uint64 `uri:"id" binding:"gt=0"`
+		AccAddress string `uri:"acc_address" description:"Bech32 account address of the client"`
+		ID         uint64 `uri:"id" binding:"gt=0" description:"Session ID"`
 	}
 	Body struct {
-		Key       string `json:"key"`
-		Signature string `json:"signature"`
+		Key       string `json:"key" description:"Base64 encoded service-specific peer key"`
+		Signature string `json:"signature" description:"Base64 encoded signature of the big-endian session ID"`
+		Type      uint64 `json:"type,omitempty" description:"Service type; defaults to the first service of the node"`
 	}
 }
 
@@ -46,3 +47,77 @@ func NewRequestAddSession(c *gin.Context) (req *RequestAddSession, err error) {
 
 	return req, nil
 }
+
+type RequestRekeySession struct {
+	AccAddress sdk.AccAddress
+	Key        []byte
+	Signature  []byte
+
+	URI struct {
+		AccAddress string `uri:"acc_address" description:"Bech32 account address of the client"`
+		ID         uint64 `uri:"id" binding:"gt=0" description:"Session ID"`
+	}
+	Body struct {
+		Key       string `json:"key" binding:"required" description:"Base64 encoded new service-specific peer key"`
+		Signature string `json:"signature" binding:"required" description:"Base64 encoded signature of the big-endian session ID followed by the new key"`
+	}
+}
+
+func NewRequestRekeySession(c *gin.Context) (req *RequestRekeySession, err error) {
+	req = &RequestRekeySession{}
+	if err = c.ShouldBindUri(&req.URI); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindJSON(&req.Body); err != nil {
+		return nil, err
+	}
+
+	req.AccAddress, err = sdk.AccAddressFromBech32(req.URI.AccAddress)
+	if err != nil {
+		return nil, err
+	}
+	req.Key, err = base64.StdEncoding.DecodeString(req.Body.Key)
+	if err != nil {
+		return nil, err
+	}
+	req.Signature, err = base64.StdEncoding.DecodeString(req.Body.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+type RequestGetSession struct {
+	AccAddress sdk.AccAddress
+	Signature  []byte
+
+	URI struct {
+		AccAddress string `uri:"acc_address" description:"Bech32 account address of the client"`
+		ID         uint64 `uri:"id" binding:"gt=0" description:"Session ID"`
+	}
+	Query struct {
+		Signature string `form:"signature" binding:"required" description:"Base64 encoded signature of the big-endian session ID"`
+	}
+}
+
+func NewRequestGetSession(c *gin.Context) (req *RequestGetSession, err error) {
+	req = &RequestGetSession{}
+	if err = c.ShouldBindUri(&req.URI); err != nil {
+		return nil, err
+	}
+	if err = c.ShouldBindQuery(&req.Query); err != nil {
+		return nil, err
+	}
+
+	req.AccAddress, err = sdk.AccAddressFromBech32(req.URI.AccAddress)
+	if err != nil {
+		return nil, err
+	}
+	req.Signature, err = base64.StdEncoding.DecodeString(req.Query.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
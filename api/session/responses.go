@@ -0,0 +1,25 @@
+package session
+
+import (
+	"time"
+)
+
+type (
+	Peer struct {
+		Connected bool  `json:"connected" description:"Whether the peer is present in the service"`
+		Download  int64 `json:"download" description:"Bytes downloaded as reported by the service"`
+		Upload    int64 `json:"upload" description:"Bytes uploaded as reported by the service"`
+	}
+	ResponseGetSession struct {
+		ID           uint64    `json:"id" description:"Session ID"`
+		Subscription uint64    `json:"subscription" description:"Subscription ID"`
+		Address      string    `json:"address" description:"Bech32 account address of the client"`
+		Type         uint64    `json:"type" description:"Service type; 1 for WireGuard, 2 for V2Ray, 3 for OpenVPN, 4 for proxy"`
+		Available    int64     `json:"available" description:"Bytes available when the session was added; zero means unlimited"`
+		Download     int64     `json:"download" description:"Bytes downloaded as counted by the node"`
+		Upload       int64     `json:"upload" description:"Bytes uploaded as counted by the node"`
+		Peer         *Peer     `json:"peer"`
+		CreatedAt    time.Time `json:"created_at"`
+		UpdatedAt    time.Time `json:"updated_at"`
+	}
+)
@@ -1,11 +1,65 @@
 package session
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 
+	"github.com/sentinel-official/dvpn-node/api/middlewares"
+	"github.com/sentinel-official/dvpn-node/api/openapi"
 	"github.com/sentinel-official/dvpn-node/context"
+	"github.com/sentinel-official/dvpn-node/types"
 )
 
-func RegisterRoutes(ctx *context.Context, router gin.IRouter) {
-	router.POST("/accounts/:acc_address/sessions/:id", HandlerAddSession(ctx))
+func Routes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/accounts/:acc_address/sessions/:id",
+			OperationID: "getSession",
+			Summary:     "Get the usage of a session",
+			Tags:        []string{"session"},
+			URI:         RequestGetSession{}.URI,
+			Query:       RequestGetSession{}.Query,
+			Result:      ResponseGetSession{},
+		},
+		{
+			Method:      http.MethodPost,
+			Path:        "/accounts/:acc_address/sessions/:id",
+			OperationID: "addSession",
+			Summary:     "Add a peer for a session",
+			Tags:        []string{"session"},
+			URI:         RequestAddSession{}.URI,
+			Body:        RequestAddSession{}.Body,
+			Status:      http.StatusCreated,
+			Result:      types.PeerConfig{},
+		},
+		{
+			Method:      http.MethodPut,
+			Path:        "/accounts/:acc_address/sessions/:id",
+			OperationID: "rekeySession",
+			Summary:     "Replace the peer key of a session",
+			Tags:        []string{"session"},
+			URI:         RequestRekeySession{}.URI,
+			Body:        RequestRekeySession{}.Body,
+			Result:      types.PeerConfig{},
+		},
+	}
+}
+
+func RegisterRoutes(ctx *context.Context, routers ...gin.IRouter) {
+	var (
+		config     = ctx.Config().API
+		ban        = middlewares.BanOnInvalidSignature(config.BanThreshold, config.BanDuration)
+		ipLimit    = middlewares.RateLimitByIP(config.IPRateLimit, config.IPRateBurst)
+		accLimit   = middlewares.RateLimitByAccount(config.AccountRateLimit, config.AccountRateBurst)
+		bodyLimit  = middlewares.MaxBodySize(config.MaxBodySize)
+		concurrent = middlewares.MaxConcurrent(config.MaxConcurrentSessionAdds)
+	)
+
+	for _, r := range routers {
+		r.GET("/accounts/:acc_address/sessions/:id", ban, ipLimit, bodyLimit, HandlerGetSession(ctx))
+		r.POST("/accounts/:acc_address/sessions/:id", ban, ipLimit, accLimit, bodyLimit, concurrent, HandlerAddSession(ctx))
+		r.PUT("/accounts/:acc_address/sessions/:id", ban, ipLimit, accLimit, bodyLimit, HandlerRekeySession(ctx))
+	}
 }
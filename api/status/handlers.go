@@ -12,17 +12,28 @@ import (
 
 func HandlerGetStatus(ctx *context.Context) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		var (
+			service = ctx.Services().Default()
+			health  = service.Health()
+		)
+
 		item := &ResponseGetStatus{
 			Address: ctx.Address().String(),
 			Bandwidth: &Bandwidth{
 				Upload:   ctx.Bandwidth().Upload.Int64(),
 				Download: ctx.Bandwidth().Download.Int64(),
 			},
+			Endpoints: service.Endpoints(),
 			Handshake: &Handshake{
 				Enable: ctx.Config().Handshake.Enable,
 				Peers:  ctx.Config().Handshake.Peers,
 			},
+			Health: &Health{
+				Running:  health.Running,
+				Restarts: health.Restarts,
+			},
 			IntervalSetSessions:    ctx.IntervalSetSessions(),
+			IntervalUpdatePlans:    ctx.IntervalUpdatePlans(),
 			IntervalUpdateSessions: ctx.IntervalUpdateSessions(),
 			IntervalUpdateStatus:   ctx.IntervalUpdateStatus(),
 			Location: &Location{
@@ -33,16 +44,36 @@ func HandlerGetStatus(ctx *context.Context) gin.HandlerFunc {
 			},
 			Moniker:        ctx.Moniker(),
 			Operator:       ctx.Operator().String(),
-			Peers:          ctx.Service().PeerCount(),
+			Peers:          ctx.Services().PeerCount(),
+			Plans:          ctx.Plans().IDs(),
 			GigabytePrices: ctx.GigabytePrices().String(),
 			HourlyPrices:   ctx.HourlyPrices().String(),
 			QOS: &QOS{
-				MaxPeers: ctx.Config().QOS.MaxPeers,
+				IdleTimeout:          ctx.Config().QOS.IdleTimeout,
+				MaxDevicesPerAccount: ctx.Config().QOS.MaxDevicesPerAccount,
+				MaxPeers:             ctx.Config().QOS.MaxPeers,
+				MaxPeersPerService:   ctx.Config().QOS.MaxPeersPerService,
 			},
-			Type:    ctx.Service().Type(),
+			Type:    service.Type(),
 			Version: version.Version,
 		}
 
+		for _, service := range ctx.Services().Items() {
+			health := service.Health()
+			item.Services = append(
+				item.Services,
+				&Service{
+					Type:  service.Type(),
+					Peers: service.PeerCount(),
+					Health: &Health{
+						Running:  health.Running,
+						Restarts: health.Restarts,
+					},
+					Endpoints: service.Endpoints(),
+				},
+			)
+		}
+
 		c.JSON(http.StatusOK, types.NewResponseResult(item))
 	}
 }
@@ -2,6 +2,8 @@ package status
 
 import (
 	"time"
+
+	"github.com/sentinel-official/dvpn-node/types"
 )
 
 type (
@@ -9,6 +11,10 @@ type (
 		Download int64 `json:"download"`
 		Upload   int64 `json:"upload"`
 	}
+	Health struct {
+		Running  bool   `json:"running"`
+		Restarts uint64 `json:"restarts"`
+	}
 	Handshake struct {
 		Enable bool   `json:"enable"`
 		Peers  uint64 `json:"peers"`
@@ -20,23 +26,37 @@ type (
 		Longitude float64 `json:"longitude"`
 	}
 	QOS struct {
-		MaxPeers int `json:"max_peers"`
+		IdleTimeout          time.Duration `json:"idle_timeout"`
+		MaxDevicesPerAccount int           `json:"max_devices_per_account"`
+		MaxPeers             int           `json:"max_peers"`
+		MaxPeersPerService   int           `json:"max_peers_per_service"`
+	}
+	Service struct {
+		Type      uint64                  `json:"type" description:"Service type; 1 for WireGuard, 2 for V2Ray, 3 for OpenVPN, 4 for proxy"`
+		Peers     int                     `json:"peers"`
+		Health    *Health                 `json:"health"`
+		Endpoints []types.ServiceEndpoint `json:"endpoints"`
 	}
 	ResponseGetStatus struct {
-		Address                string        `json:"address"`
-		Bandwidth              *Bandwidth    `json:"bandwidth"`
-		Handshake              *Handshake    `json:"handshake"`
-		IntervalSetSessions    time.Duration `json:"interval_set_sessions"`
-		IntervalUpdateSessions time.Duration `json:"interval_update_sessions"`
-		IntervalUpdateStatus   time.Duration `json:"interval_update_status"`
-		Location               *Location     `json:"location"`
-		Moniker                string        `json:"moniker"`
-		Operator               string        `json:"operator"`
-		Peers                  int           `json:"peers"`
-		GigabytePrices         string        `json:"gigabyte_prices"`
-		HourlyPrices           string        `json:"hourly_prices"`
-		QOS                    *QOS          `json:"qos"`
-		Type                   uint64        `json:"type"`
-		Version                string        `json:"version"`
+		Address                string                  `json:"address"`
+		Bandwidth              *Bandwidth              `json:"bandwidth"`
+		Endpoints              []types.ServiceEndpoint `json:"endpoints" description:"Endpoints of the default service"`
+		Handshake              *Handshake              `json:"handshake"`
+		Health                 *Health                 `json:"health" description:"Health of the default service"`
+		IntervalSetSessions    time.Duration           `json:"interval_set_sessions"`
+		IntervalUpdatePlans    time.Duration           `json:"interval_update_plans"`
+		IntervalUpdateSessions time.Duration           `json:"interval_update_sessions"`
+		IntervalUpdateStatus   time.Duration           `json:"interval_update_status"`
+		Location               *Location               `json:"location"`
+		Moniker                string                  `json:"moniker"`
+		Operator               string                  `json:"operator"`
+		Peers                  int                     `json:"peers"`
+		Plans                  []uint64                `json:"plans" description:"IDs of the plans the node belongs to"`
+		GigabytePrices         string                  `json:"gigabyte_prices"`
+		HourlyPrices           string                  `json:"hourly_prices"`
+		QOS                    *QOS                    `json:"qos"`
+		Services               []*Service              `json:"services"`
+		Type                   uint64                  `json:"type" description:"Type of the default service, which serves the requests without a type; 1 for WireGuard, 2 for V2Ray, 3 for OpenVPN, 4 for proxy. The services list has every configured service"`
+		Version                string                  `json:"version"`
 	}
 )
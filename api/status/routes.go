@@ -1,11 +1,29 @@
 package status
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
 
+	"github.com/sentinel-official/dvpn-node/api/openapi"
 	"github.com/sentinel-official/dvpn-node/context"
 )
 
-func RegisterRoutes(ctx *context.Context, r gin.IRouter) {
-	r.GET("/status", HandlerGetStatus(ctx))
+func Routes() []openapi.Route {
+	return []openapi.Route{
+		{
+			Method:      http.MethodGet,
+			Path:        "/status",
+			OperationID: "getStatus",
+			Summary:     "Get the status of the node",
+			Tags:        []string{"status"},
+			Result:      ResponseGetStatus{},
+		},
+	}
+}
+
+func RegisterRoutes(ctx *context.Context, routers ...gin.IRouter) {
+	for _, r := range routers {
+		r.GET("/status", HandlerGetStatus(ctx))
+	}
 }
@@ -80,7 +80,16 @@ func configShow() *cobra.Command {
 				return err
 			}
 
+			overrides, err := types.EnvOverrides()
+			if err != nil {
+				return err
+			}
+
 			fmt.Println(config.String())
+			for _, item := range overrides {
+				fmt.Printf("# %s is set by the environment variable %s\n", item.Key, item.Env)
+			}
+
 			return nil
 		},
 	}
@@ -102,7 +111,7 @@ func configSet() *cobra.Command {
 			v := viper.New()
 			v.SetConfigFile(configPath)
 
-			config, err := types.ReadInConfig(v)
+			config, err := types.ReadInConfigFile(v)
 			if err != nil {
 				return err
 			}
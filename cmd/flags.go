@@ -2,6 +2,8 @@ package cmd
 
 const (
 	flagAccount              = "account"
+	flagGigabytePrices       = "gigabyte-prices"
+	flagHourlyPrices         = "hourly-prices"
 	flagIndex                = "index"
 	flagRecover              = "recover"
 	flagSkipConfigValidation = "skip-config-validation"
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/sentinel-official/dvpn-node/api"
+	"github.com/sentinel-official/dvpn-node/api/prices"
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+const (
+	pricesRequestTimeout = 2 * time.Minute
+)
+
+func PricesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prices",
+		Short: "Prices sub-commands",
+	}
+
+	cmd.AddCommand(
+		pricesUpdate(),
+	)
+
+	return cmd
+}
+
+func pricesUpdate() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the prices of the running node and save them to the configuration",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var (
+				home       = viper.GetString(flags.FlagHome)
+				configPath = filepath.Join(home, types.ConfigFileName)
+			)
+
+			v := viper.New()
+			v.SetConfigFile(configPath)
+
+			config, err := types.ReadInConfig(v)
+			if err != nil {
+				return err
+			}
+
+			var (
+				gigabytePrices = config.Node.GigabytePrices
+				hourlyPrices   = config.Node.HourlyPrices
+			)
+
+			if cmd.Flags().Changed(flagGigabytePrices) {
+				gigabytePrices, err = cmd.Flags().GetString(flagGigabytePrices)
+				if err != nil {
+					return err
+				}
+			}
+			if cmd.Flags().Changed(flagHourlyPrices) {
+				hourlyPrices, err = cmd.Flags().GetString(flagHourlyPrices)
+				if err != nil {
+					return err
+				}
+			}
+
+			reader := bufio.NewReader(cmd.InOrStdin())
+
+			kr, err := keyring.New(types.KeyringName, config.Keyring.Backend, home, reader)
+			if err != nil {
+				return err
+			}
+
+			timestamp := time.Now().Unix()
+
+			signature, _, err := kr.Sign(config.Keyring.From, prices.SignBytes(timestamp, gigabytePrices, hourlyPrices))
+			if err != nil {
+				return err
+			}
+
+			var body prices.RequestUpdatePrices
+			body.Body.GigabytePrices = gigabytePrices
+			body.Body.HourlyPrices = hourlyPrices
+			body.Body.Timestamp = timestamp
+			body.Body.Signature = base64.StdEncoding.EncodeToString(signature)
+
+			buf, err := json.Marshal(body.Body)
+			if err != nil {
+				return err
+			}
+
+			host, port, err := net.SplitHostPort(config.Node.ListenOn)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+				host = "127.0.0.1"
+			}
+
+			req, err := http.NewRequest(
+				http.MethodPut,
+				fmt.Sprintf("https://%s%s/prices", net.JoinHostPort(host, port), api.BasePath),
+				bytes.NewReader(buf),
+			)
+			if err != nil {
+				return err
+			}
+
+			req.Header.Set("Content-Type", types.ContentType)
+
+			// The node serves a self-signed certificate, while the request
+			// itself is authenticated by the signature of the operator.
+			client := &http.Client{
+				Timeout: pricesRequestTimeout,
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						InsecureSkipVerify: true, // nolint:gosec
+					},
+				},
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				return err
+			}
+
+			defer resp.Body.Close()
+
+			var (
+				result prices.ResponseUpdatePrices
+				res    = types.Response{Result: &result}
+			)
+
+			if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+				return err
+			}
+			if !res.Success {
+				return fmt.Errorf("failed to update the prices: %v", res.Error)
+			}
+
+			// The file is read in again, leaving out the values overridden
+			// by the environment.
+			v = viper.New()
+			v.SetConfigFile(configPath)
+
+			config, err = types.ReadInConfigFile(v)
+			if err != nil {
+				return err
+			}
+
+			config.Node.GigabytePrices = result.GigabytePrices
+			config.Node.HourlyPrices = result.HourlyPrices
+
+			if err = config.SaveToPath(configPath); err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "gigabyte_prices: %s\nhourly_prices: %s\nupdated: %t\n",
+				result.GigabytePrices, result.HourlyPrices, result.Updated)
+			return err
+		},
+	}
+
+	cmd.Flags().String(flagGigabytePrices, "", "per gigabyte prices, e.g. 1000udvpn")
+	cmd.Flags().String(flagHourlyPrices, "", "per hour prices, e.g. 100udvpn")
+
+	return cmd
+}
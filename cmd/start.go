@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"net/http"
+	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/cosmos/cosmos-sdk/client/flags"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
@@ -23,7 +27,12 @@ import (
 	"github.com/sentinel-official/dvpn-node/libs/geoip"
 	"github.com/sentinel-official/dvpn-node/lite"
 	"github.com/sentinel-official/dvpn-node/node"
+	"github.com/sentinel-official/dvpn-node/services/openvpn"
+	ovpntypes "github.com/sentinel-official/dvpn-node/services/openvpn/types"
+	"github.com/sentinel-official/dvpn-node/services/proxy"
+	proxytypes "github.com/sentinel-official/dvpn-node/services/proxy/types"
 	"github.com/sentinel-official/dvpn-node/services/v2ray"
+	v2raytypes "github.com/sentinel-official/dvpn-node/services/v2ray/types"
 	"github.com/sentinel-official/dvpn-node/services/wireguard"
 	wgtypes "github.com/sentinel-official/dvpn-node/services/wireguard/types"
 	"github.com/sentinel-official/dvpn-node/types"
@@ -41,6 +50,36 @@ func runHandshake(peers uint64) error {
 			"--rs-host 0.0.0.0:53", peers), " ")...).Run()
 }
 
+// reloadConfig applies the configuration file to the running node, refusing
+// the changes of the service configuration files, which are read in once.
+func reloadConfig(ctx *context.Context, configPath string, serviceConfigs map[string][]byte, validate bool) error {
+	for path, data := range serviceConfigs {
+		buf, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(buf, data) {
+			return fmt.Errorf("changing %s requires a restart of the node", filepath.Base(path))
+		}
+	}
+
+	v := viper.New()
+	v.SetConfigFile(configPath)
+
+	config, err := types.ReadInConfig(v)
+	if err != nil {
+		return err
+	}
+
+	if validate {
+		if err = config.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return ctx.Reload(config)
+}
+
 func StartCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "start",
@@ -57,6 +96,12 @@ func StartCmd() *cobra.Command {
 				return err
 			}
 
+			// The handler is registered first, as the default action of the
+			// signal would stop the node while it starts. A signal received
+			// in the meantime is applied once the node has been initialized.
+			hup := make(chan os.Signal, 1)
+			signal.Notify(hup, syscall.SIGHUP)
+
 			v := viper.New()
 			v.SetConfigFile(configPath)
 
@@ -78,23 +123,44 @@ func StartCmd() *cobra.Command {
 				}
 			}
 
-			var service types.Service
-			if config.Node.Type == "wireguard" {
-				log.Info("Creating the IPv4 pool", "CIDR", types.IPv4CIDR)
-				ipv4Pool, err := wgtypes.NewIPv4PoolFromCIDR(types.IPv4CIDR)
-				if err != nil {
-					return err
+			var (
+				services           = types.NewServices()
+				serviceConfigPaths []string
+			)
+
+			for _, t := range config.Node.Types() {
+				var service types.Service
+				if t == "wireguard" {
+					log.Info("Creating the IPv4 pool", "CIDR", types.IPv4CIDR)
+					ipv4Pool, err := wgtypes.NewIPv4PoolFromCIDR(types.IPv4CIDR)
+					if err != nil {
+						return err
+					}
+
+					log.Info("Creating the IPv6 pool", "CIDR", types.IPv6CIDR)
+					ipv6Pool, err := wgtypes.NewIPv6PoolFromCIDR(types.IPv6CIDR)
+					if err != nil {
+						return err
+					}
+
+					service = wireguard.NewWireGuard(wgtypes.NewIPPool(ipv4Pool, ipv6Pool))
+					serviceConfigPaths = append(serviceConfigPaths, filepath.Join(home, wgtypes.ConfigFileName))
+				} else if t == "v2ray" {
+					service = v2ray.NewV2Ray(log)
+					serviceConfigPaths = append(serviceConfigPaths, filepath.Join(home, v2raytypes.ConfigFileName))
+				} else if t == "openvpn" {
+					service = openvpn.NewOpenVPN(log)
+					serviceConfigPaths = append(serviceConfigPaths, filepath.Join(home, ovpntypes.ConfigFileName))
+				} else if t == "proxy" {
+					service = proxy.NewProxy(log)
+					serviceConfigPaths = append(serviceConfigPaths, filepath.Join(home, proxytypes.ConfigFileName))
+				} else {
+					return fmt.Errorf("invalid service type %s", t)
 				}
 
-				log.Info("Creating the IPv6 pool", "CIDR", types.IPv6CIDR)
-				ipv6Pool, err := wgtypes.NewIPv6PoolFromCIDR(types.IPv6CIDR)
-				if err != nil {
+				if err = services.Add(service); err != nil {
 					return err
 				}
-
-				service = wireguard.NewWireGuard(wgtypes.NewIPPool(ipv4Pool, ipv6Pool))
-			} else if config.Node.Type == "v2ray" {
-				service = v2ray.NewV2Ray()
 			}
 
 			var (
@@ -161,16 +227,34 @@ func StartCmd() *cobra.Command {
 				}()
 			}
 
-			log.Info("Initializing the VPN service", "type", service.Type())
-			if err = service.Init(home); err != nil {
-				return err
+			for _, service := range services.Items() {
+				log.Info("Initializing the VPN service", "type", service.Type())
+				if err = service.Init(home); err != nil {
+					return err
+				}
 			}
 
-			log.Info("Starting the VPN service", "type", service.Type())
-			if err = service.Start(); err != nil {
+			if err = services.ValidatePorts(); err != nil {
 				return err
 			}
 
+			for _, service := range services.Items() {
+				log.Info("Starting the VPN service", "type", service.Type())
+				if err = service.Start(); err != nil {
+					return err
+				}
+			}
+
+			// The contents are read after the initialization, which may fill
+			// in the service configuration files.
+			serviceConfigs := make(map[string][]byte)
+			for _, path := range serviceConfigPaths {
+				serviceConfigs[path], err = os.ReadFile(path)
+				if err != nil {
+					return err
+				}
+			}
+
 			log.Info("Opening the database", "path", databasePath)
 			database, err := gorm.Open(
 				sqlite.Open(databasePath),
@@ -188,6 +272,18 @@ func StartCmd() *cobra.Command {
 				return err
 			}
 
+			// The sessions stored before the services were typed belong to the
+			// only service the node ran then, so they are stamped while that
+			// is still the case.
+			if services.Len() == 1 {
+				result := database.Model(&types.Session{}).
+					Where("type = ?", 0).
+					Update("type", services.Default().Type())
+				if result.Error != nil {
+					return result.Error
+				}
+			}
+
 			var (
 				ctx            = context.NewContext()
 				router         = gin.New()
@@ -197,6 +293,7 @@ func StartCmd() *cobra.Command {
 						AllowMethods: []string{
 							http.MethodGet,
 							http.MethodPost,
+							http.MethodPut,
 						},
 						AllowHeaders: []string{
 							types.ContentType,
@@ -205,9 +302,6 @@ func StartCmd() *cobra.Command {
 				)
 			)
 
-			router.Use(corsMiddleware)
-			api.RegisterRoutes(ctx, router)
-
 			ctx = ctx.WithBandwidth(bandwidth).
 				WithClient(client).
 				WithConfig(config).
@@ -215,13 +309,31 @@ func StartCmd() *cobra.Command {
 				WithHandler(router).
 				WithLocation(location).
 				WithLogger(log).
-				WithService(service)
+				WithPlans(types.NewPlans()).
+				WithServices(services)
+
+			if err = router.SetTrustedProxies(config.API.TrustedProxyList()); err != nil {
+				return err
+			}
+
+			router.Use(corsMiddleware)
+			api.RegisterRoutes(ctx, router)
 
 			n := node.NewNode(ctx)
 			if err = n.Initialize(); err != nil {
 				return err
 			}
 
+			go func() {
+				for range hup {
+					log.Info("Reloading the configuration file", "path", configPath)
+					log.Info("The log level is not reloaded, restart the node with the flag to change it", "log_level", viper.GetString(flags.FlagLogLevel))
+					if err := reloadConfig(ctx, configPath, serviceConfigs, !skipConfigValidation); err != nil {
+						log.Error("failed to reload the configuration", "error", err)
+					}
+				}
+			}()
+
 			return n.Start(home)
 		},
 	}
@@ -3,6 +3,8 @@ package context
 import (
 	"net"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -16,14 +18,17 @@ import (
 )
 
 type Context struct {
-	bandwidth *hubtypes.Bandwidth
-	client    *lite.Client
-	config    *types.Config
-	database  *gorm.DB
-	handler   http.Handler
-	location  *geoiptypes.GeoIPLocation
-	logger    tmlog.Logger
-	service   types.Service
+	mutex       sync.RWMutex
+	pricesMutex sync.Mutex
+	bandwidth   *hubtypes.Bandwidth
+	client      *lite.Client
+	config      *types.Config
+	database    *gorm.DB
+	handler     http.Handler
+	location    *geoiptypes.GeoIPLocation
+	logger      tmlog.Logger
+	plans       *types.Plans
+	services    *types.Services
 }
 
 func NewContext() *Context {
@@ -37,12 +42,12 @@ func (c *Context) WithDatabase(v *gorm.DB) *Context                  { c.databas
 func (c *Context) WithHandler(v http.Handler) *Context               { c.handler = v; return c }
 func (c *Context) WithLocation(v *geoiptypes.GeoIPLocation) *Context { c.location = v; return c }
 func (c *Context) WithLogger(v tmlog.Logger) *Context                { c.logger = v; return c }
-func (c *Context) WithService(v types.Service) *Context              { c.service = v; return c }
+func (c *Context) WithPlans(v *types.Plans) *Context                 { c.plans = v; return c }
+func (c *Context) WithServices(v *types.Services) *Context           { c.services = v; return c }
 
 func (c *Context) Address() hubtypes.NodeAddress       { return c.Operator().Bytes() }
 func (c *Context) Bandwidth() *hubtypes.Bandwidth      { return c.bandwidth }
 func (c *Context) Client() *lite.Client                { return c.client }
-func (c *Context) Config() *types.Config               { return c.config }
 func (c *Context) Database() *gorm.DB                  { return c.database }
 func (c *Context) Handler() http.Handler               { return c.handler }
 func (c *Context) IntervalSetSessions() time.Duration  { return c.Config().Node.IntervalSetSessions }
@@ -52,8 +57,22 @@ func (c *Context) Location() *geoiptypes.GeoIPLocation { return c.location }
 func (c *Context) Log() tmlog.Logger                   { return c.logger }
 func (c *Context) Moniker() string                     { return c.Config().Node.Moniker }
 func (c *Context) Operator() sdk.AccAddress            { return c.client.FromAddress() }
+func (c *Context) Plans() *types.Plans                 { return c.plans }
 func (c *Context) RemoteURL() string                   { return c.Config().Node.RemoteURL }
-func (c *Context) Service() types.Service              { return c.service }
+func (c *Context) Services() *types.Services           { return c.services }
+
+// Config returns the live configuration, which is replaced as a whole on the
+// updates and never modified in place.
+func (c *Context) Config() *types.Config {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.config
+}
+
+func (c *Context) IntervalUpdatePlans() time.Duration {
+	return c.Config().Node.IntervalUpdatePlans
+}
 
 func (c *Context) IntervalUpdateSessions() time.Duration {
 	return c.Config().Node.IntervalUpdateSessions
@@ -68,6 +87,16 @@ func (c *Context) IPv4Address() net.IP {
 	return net.ParseIP(addr).To4()
 }
 
+// RemoteHost returns the host of the public URL, which the clients connect to.
+func (c *Context) RemoteHost() string {
+	remoteURL, err := url.Parse(c.RemoteURL())
+	if err != nil {
+		panic(err)
+	}
+
+	return remoteURL.Hostname()
+}
+
 func (c *Context) GigabytePrices() sdk.Coins {
 	if c.Config().Node.GigabytePrices == "" {
 		return nil
@@ -0,0 +1,36 @@
+package context
+
+func (c *Context) UpdatePlans() error {
+	c.Log().Info("Updating the plans...")
+
+	ids, err := c.Client().QueryPlansForNode(c.Address())
+	if err != nil {
+		c.Log().Error("failed to query the plans for node", "error", err)
+		return err
+	}
+
+	c.Plans().Set(ids)
+	c.Log().Info("Updated the plans", "count", len(ids))
+
+	return nil
+}
+
+// HasNodeForPlan answers from the cached plans, querying the store only for
+// the plans missing in there, which the node may have been added to since the
+// last update. A plan the node has been removed from is answered from the
+// cache until the next update replaces it.
+func (c *Context) HasNodeForPlan(id uint64) (bool, error) {
+	if c.Plans().Has(id) {
+		return true, nil
+	}
+
+	ok, err := c.Client().HasNodeForPlan(id, c.Address())
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		c.Plans().Add(id)
+	}
+
+	return ok, nil
+}
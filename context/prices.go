@@ -0,0 +1,71 @@
+package context
+
+import (
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+	nodetypes "github.com/sentinel-official/hub/x/node/types"
+)
+
+var (
+	ErrPricesCleared = errors.New("prices once set cannot be cleared")
+)
+
+// UpdatePrices broadcasts the prices when they differ from the current ones,
+// reporting whether they did. The chain leaves the empty prices unchanged, so
+// the prices once set cannot be cleared. The updates are serialized, so the
+// prices compared are the ones stored after the broadcast.
+func (c *Context) UpdatePrices(gigabytePrices, hourlyPrices sdk.Coins) (bool, error) {
+	c.pricesMutex.Lock()
+	defer c.pricesMutex.Unlock()
+
+	return c.updatePrices(gigabytePrices, hourlyPrices)
+}
+
+// updatePrices is UpdatePrices with the prices mutex held by the caller.
+func (c *Context) updatePrices(gigabytePrices, hourlyPrices sdk.Coins) (bool, error) {
+	var (
+		currentGigabytePrices = c.GigabytePrices()
+		currentHourlyPrices   = c.HourlyPrices()
+	)
+
+	if gigabytePrices.String() == currentGigabytePrices.String() &&
+		hourlyPrices.String() == currentHourlyPrices.String() {
+		return false, nil
+	}
+	if gigabytePrices.Empty() && !currentGigabytePrices.Empty() {
+		return false, errors.Wrap(ErrPricesCleared, "invalid gigabyte_prices")
+	}
+	if hourlyPrices.Empty() && !currentHourlyPrices.Empty() {
+		return false, errors.Wrap(ErrPricesCleared, "invalid hourly_prices")
+	}
+
+	c.Log().Info("Updating the node prices...", "gigabyte_prices", gigabytePrices, "hourly_prices", hourlyPrices)
+
+	_, err := c.Client().Tx(
+		nodetypes.NewMsgUpdateDetailsRequest(
+			c.Address(),
+			gigabytePrices,
+			hourlyPrices,
+			c.RemoteURL(),
+		),
+	)
+	if err != nil {
+		c.Log().Error("failed to update the node prices", "error", err)
+		return false, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var (
+		config = *c.config
+		node   = *config.Node
+	)
+
+	node.GigabytePrices = gigabytePrices.String()
+	node.HourlyPrices = hourlyPrices.String()
+	config.Node = &node
+	c.config = &config
+
+	return true, nil
+}
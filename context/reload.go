@@ -0,0 +1,90 @@
+package context
+
+import (
+	"fmt"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pkg/errors"
+
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+// reloadable lists the configuration values applied to the running node, the
+// rest being fixed in the services, the API and the client at the start.
+var reloadable = map[string]bool{
+	"node.interval_set_sessions":    true,
+	"node.interval_update_plans":    true,
+	"node.interval_update_sessions": true,
+	"node.interval_update_status":   true,
+	"node.ipv4_address":             true,
+	"node.moniker":                  true,
+	"node.gigabyte_prices":          true,
+	"node.hourly_prices":            true,
+	"qos.idle_timeout":              true,
+	"qos.max_devices_per_account":   true,
+	"qos.max_peers":                 true,
+	"qos.max_peers_per_service":     true,
+}
+
+// Reload replaces the live configuration with the given one, broadcasting the
+// prices first when they changed. The configuration is left as it is when
+// any of the changed values requires a restart. It holds the prices mutex
+// throughout, so the diff is not stale against a concurrent price update.
+func (c *Context) Reload(config *types.Config) error {
+	c.pricesMutex.Lock()
+	defer c.pricesMutex.Unlock()
+
+	changes := c.Config().Diff(config)
+	if len(changes) == 0 {
+		c.Log().Info("The configuration is unchanged")
+		return nil
+	}
+
+	var keys []string
+	for _, change := range changes {
+		if !reloadable[change.Key] {
+			keys = append(keys, change.Key)
+		}
+	}
+	if len(keys) > 0 {
+		return fmt.Errorf("changing %s requires a restart of the node", strings.Join(keys, ", "))
+	}
+
+	gigabytePrices, err := sdk.ParseCoinsNormalized(config.Node.GigabytePrices)
+	if err != nil {
+		return errors.Wrap(err, "invalid gigabyte_prices")
+	}
+
+	hourlyPrices, err := sdk.ParseCoinsNormalized(config.Node.HourlyPrices)
+	if err != nil {
+		return errors.Wrap(err, "invalid hourly_prices")
+	}
+
+	if gigabytePrices.String() != c.GigabytePrices().String() ||
+		hourlyPrices.String() != c.HourlyPrices().String() {
+		params, err := c.Client().QueryNodeParams()
+		if err != nil {
+			return errors.Wrap(err, "failed to query the node params")
+		}
+		if err = types.ValidatePrices(gigabytePrices, params.MinGigabytePrices, params.MaxGigabytePrices); err != nil {
+			return errors.Wrap(err, "invalid gigabyte_prices")
+		}
+		if err = types.ValidatePrices(hourlyPrices, params.MinHourlyPrices, params.MaxHourlyPrices); err != nil {
+			return errors.Wrap(err, "invalid hourly_prices")
+		}
+		if _, err = c.updatePrices(gigabytePrices, hourlyPrices); err != nil {
+			return err
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.config = config
+	for _, change := range changes {
+		c.Log().Info("Reloaded the configuration", "key", change.Key, "old", change.Old, "new", change.New)
+	}
+
+	return nil
+}
@@ -2,44 +2,68 @@ package context
 
 import (
 	"encoding/base64"
+	"fmt"
+
+	"github.com/sentinel-official/dvpn-node/types"
 )
 
-func (c *Context) RemovePeer(key string) error {
-	c.Log().Info("Removing the peer from service", "key", key)
+func (c *Context) Service(t uint64) (types.Service, error) {
+	service := c.Services().Get(t)
+	if service == nil {
+		return nil, fmt.Errorf("service type %d does not exist", t)
+	}
+
+	return service, nil
+}
+
+func (c *Context) RemovePeer(t uint64, key string) error {
+	c.Log().Info("Removing the peer from service", "type", t)
 
 	data, err := base64.StdEncoding.DecodeString(key)
 	if err != nil {
-		c.Log().Error("failed to decode the key", "error", err, "key", key)
+		c.Log().Error("failed to decode the key", "error", err)
 		return err
 	}
 
-	if err = c.Service().RemovePeer(data); err != nil {
-		c.Log().Error("failed to remove the peer from service", "error", err, "data", data)
+	service, err := c.Service(t)
+	if err != nil {
+		c.Log().Error("failed to get the service", "error", err, "type", t)
+		return err
+	}
+
+	if err = service.RemovePeer(data); err != nil {
+		c.Log().Error("failed to remove the peer from service", "error", err, "type", t)
 		return err
 	}
 
 	return nil
 }
 
-func (c *Context) HasPeer(key string) (bool, error) {
+func (c *Context) HasPeer(t uint64, key string) (bool, error) {
 	data, err := base64.StdEncoding.DecodeString(key)
 	if err != nil {
-		c.Log().Error("failed to decode the key", "error", err, "key", key)
+		c.Log().Error("failed to decode the key", "error", err)
+		return false, err
+	}
+
+	service, err := c.Service(t)
+	if err != nil {
+		c.Log().Error("failed to get the service", "error", err, "type", t)
 		return false, err
 	}
 
-	return c.Service().HasPeer(data), nil
+	return service.HasPeer(data), nil
 }
 
-func (c *Context) RemovePeerIfExists(key string) error {
-	ok, err := c.HasPeer(key)
+func (c *Context) RemovePeerIfExists(t uint64, key string) error {
+	ok, err := c.HasPeer(t, key)
 	if err != nil {
 		return err
 	}
 	if !ok {
-		c.Log().Debug("Peer does not exist", "key", key)
+		c.Log().Debug("Peer does not exist", "type", t)
 		return nil
 	}
 
-	return c.RemovePeer(key)
+	return c.RemovePeer(t, key)
 }
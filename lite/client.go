@@ -149,5 +149,6 @@ func (c *Client) WithTxConfig(v client.TxConfig) *Client {
 
 func (c *Client) FromAddress() sdk.AccAddress { return c.ctx.FromAddress }
 func (c *Client) FromName() string            { return c.ctx.FromName }
+func (c *Client) Keyring() keyring.Keyring    { return c.ctx.Keyring }
 func (c *Client) SimulateAndExecute() bool    { return c.txf.SimulateAndExecute() }
 func (c *Client) TxConfig() client.TxConfig   { return c.ctx.TxConfig }
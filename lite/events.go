@@ -0,0 +1,100 @@
+package lite
+
+import (
+	"context"
+	"encoding/json"
+
+	jsonrpcclient "github.com/tendermint/tendermint/rpc/jsonrpc/client"
+)
+
+const (
+	eventsCapacity = 64
+)
+
+func (c *Client) subscribeEvents(ctx context.Context, remote string, queries []string) (<-chan map[string][]string, error) {
+	c.log.Debug("Subscribing to the events", "remote", remote, "queries", queries)
+
+	// A reconnected client would not carry the subscriptions over, so the
+	// client is stopped instead, which closes the channel of the responses.
+	var ws *jsonrpcclient.WSClient
+	ws, err := jsonrpcclient.NewWS(
+		remote, "/websocket",
+		jsonrpcclient.MaxReconnectAttempts(0),
+		jsonrpcclient.OnReconnect(func() { _ = ws.Stop() }),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err = ws.Start(); err != nil {
+		return nil, err
+	}
+
+	for _, query := range queries {
+		if err = ws.Subscribe(ctx, query); err != nil {
+			_ = ws.Stop()
+			return nil, err
+		}
+	}
+
+	events := make(chan map[string][]string, eventsCapacity)
+	go func() {
+		defer close(events)
+		defer func() { _ = ws.Stop() }()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case res, ok := <-ws.ResponsesCh:
+				if !ok {
+					return
+				}
+				if res.Error != nil {
+					c.log.Error("failed to subscribe to the events", "remote", remote, "error", res.Error)
+					return
+				}
+
+				var result struct {
+					Events map[string][]string `json:"events"`
+				}
+
+				if err := json.Unmarshal(res.Result, &result); err != nil {
+					c.log.Error("failed to decode the event", "remote", remote, "error", err)
+					continue
+				}
+
+				// The subscriptions are acknowledged with empty results.
+				if len(result.Events) == 0 {
+					continue
+				}
+
+				select {
+				case events <- result.Events:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// SubscribeEvents subscribes to the events matching the queries on the first
+// reachable remote. The events are delivered as the attribute values keyed by
+// the event type and the attribute key, and the channel is closed once the
+// connection is lost or the context is done.
+func (c *Client) SubscribeEvents(ctx context.Context, queries ...string) (result <-chan map[string][]string, err error) {
+	c.log.Info("Subscribing to the events", "queries", queries)
+	for i := 0; i < len(c.remotes); i++ {
+		result, err = c.subscribeEvents(ctx, c.remotes[i], queries)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
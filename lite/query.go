@@ -4,9 +4,11 @@ import (
 	"context"
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/query"
 	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
 	hubtypes "github.com/sentinel-official/hub/types"
 	nodetypes "github.com/sentinel-official/hub/x/node/types"
+	plantypes "github.com/sentinel-official/hub/x/plan/types"
 	sessiontypes "github.com/sentinel-official/hub/x/session/types"
 	subscriptiontypes "github.com/sentinel-official/hub/x/subscription/types"
 	vpntypes "github.com/sentinel-official/hub/x/vpn/types"
@@ -15,6 +17,11 @@ import (
 	"github.com/sentinel-official/dvpn-node/types"
 )
 
+const (
+	plansPageLimit = 100
+	maxPlanLookups = 1000
+)
+
 func (c *Client) queryAccount(remote string, accAddr sdk.AccAddress) (authtypes.AccountI, error) {
 	c.log.Debug("Querying the account", "remote", remote, "address", accAddr)
 
@@ -222,6 +229,45 @@ func (c *Client) QuerySession(id uint64) (result *sessiontypes.Session, err erro
 	return result, nil
 }
 
+func (c *Client) queryNodeParams(remote string) (*nodetypes.Params, error) {
+	c.log.Debug("Querying the node params", "remote", remote)
+
+	client, err := rpchttp.NewWithTimeout(remote, "/websocket", c.queryTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ctx = c.ctx.WithClient(client)
+		qc  = nodetypes.NewQueryServiceClient(ctx)
+	)
+
+	res, err := qc.QueryParams(
+		context.TODO(),
+		nodetypes.NewQueryParamsRequest(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res.Params, nil
+}
+
+func (c *Client) QueryNodeParams() (result *nodetypes.Params, err error) {
+	c.log.Info("Querying the node params")
+	for i := 0; i < len(c.remotes); i++ {
+		result, err = c.queryNodeParams(c.remotes[i])
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (c *Client) hasNodeForPlan(remote string, id uint64, nodeAddr hubtypes.NodeAddress) (bool, error) {
 	client, err := rpchttp.NewWithTimeout(remote, "/websocket", c.queryTimeout)
 	if err != nil {
@@ -260,3 +306,91 @@ func (c *Client) HasNodeForPlan(id uint64, nodeAddr hubtypes.NodeAddress) (resul
 
 	return result, nil
 }
+
+// queryPlansForNode pages through the active plans and looks up the
+// node-for-plan key of each over the same client, since the index is ordered
+// by the plan and a scan of it would return the entries of every node. The
+// lookups are capped per call; a plan left out is queried on its first use.
+func (c *Client) queryPlansForNode(remote string, nodeAddr hubtypes.NodeAddress) ([]uint64, error) {
+	c.log.Debug("Querying the plans for node", "remote", remote, "address", nodeAddr)
+
+	client, err := rpchttp.NewWithTimeout(remote, "/websocket", c.queryTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ctx       = c.ctx.WithClient(client)
+		qc        = plantypes.NewQueryServiceClient(ctx)
+		items     []uint64
+		key       []byte
+		lookups   int
+		truncated bool
+	)
+
+	for !truncated {
+		res, err := qc.QueryPlans(
+			context.TODO(),
+			plantypes.NewQueryPlansRequest(
+				hubtypes.StatusActive,
+				&query.PageRequest{
+					Key:   key,
+					Limit: plansPageLimit,
+				},
+			),
+		)
+		if err != nil {
+			return nil, types.QueryError(err)
+		}
+
+		for _, plan := range res.Plans {
+			if lookups == maxPlanLookups {
+				truncated = true
+				break
+			}
+
+			lookups++
+			value, _, err := ctx.QueryStore(
+				append(
+					[]byte(nodetypes.ModuleName+"/"),
+					nodetypes.NodeForPlanKey(plan.ID, nodeAddr)...,
+				),
+				vpntypes.ModuleName,
+			)
+			if err != nil {
+				return nil, err
+			}
+			if value != nil {
+				items = append(items, plan.ID)
+			}
+		}
+
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			break
+		}
+
+		key = res.Pagination.NextKey
+	}
+
+	if truncated {
+		c.log.Info("Reached the maximum plan lookups", "max", maxPlanLookups)
+	}
+
+	c.log.Debug("Queried the plans for node", "lookups", lookups, "count", len(items))
+	return items, nil
+}
+
+func (c *Client) QueryPlansForNode(nodeAddr hubtypes.NodeAddress) (result []uint64, err error) {
+	c.log.Info("Querying the plans for node", "address", nodeAddr)
+	for i := 0; i < len(c.remotes); i++ {
+		result, err = c.queryPlansForNode(c.remotes[i], nodeAddr)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/sentinel-official/dvpn-node/cmd"
+	openvpn "github.com/sentinel-official/dvpn-node/services/openvpn/cli"
+	proxy "github.com/sentinel-official/dvpn-node/services/proxy/cli"
 	v2ray "github.com/sentinel-official/dvpn-node/services/v2ray/cli"
 	wireguard "github.com/sentinel-official/dvpn-node/services/wireguard/cli"
 	"github.com/sentinel-official/dvpn-node/types"
@@ -23,6 +25,9 @@ func main() {
 	root.AddCommand(
 		cmd.ConfigCmd(),
 		cmd.KeysCmd(),
+		cmd.PricesCmd(),
+		openvpn.Command(),
+		proxy.Command(),
 		v2ray.Command(),
 		wireguard.Command(),
 		cmd.StartCmd(),
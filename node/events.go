@@ -0,0 +1,203 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	hubtypes "github.com/sentinel-official/hub/types"
+	sessiontypes "github.com/sentinel-official/hub/x/session/types"
+	subscriptiontypes "github.com/sentinel-official/hub/x/subscription/types"
+
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+const (
+	minPollingInterval = 1 * time.Minute
+	maxPollingInterval = 16 * time.Minute
+)
+
+var (
+	sessionEventType      = proto.MessageName(&sessiontypes.EventUpdateStatus{})
+	subscriptionEventType = proto.MessageName(&subscriptiontypes.EventUpdateStatus{})
+)
+
+// eventQueries matches the status updates of the sessions of the node and of
+// all the subscriptions, in the transactions as well as in the begin and end
+// blocks. The attribute values are JSON encoded, hence CONTAINS for the address.
+func (n *Node) eventQueries() []string {
+	var queries []string
+	for _, event := range []string{"Tx", "NewBlockHeader"} {
+		queries = append(queries,
+			fmt.Sprintf("tm.event = '%s' AND %s.node_address CONTAINS '%s'", event, sessionEventType, n.Address()),
+			fmt.Sprintf("tm.event = '%s' AND %s.id EXISTS", event, subscriptionEventType),
+		)
+	}
+
+	return queries
+}
+
+// jobWatchEvents removes the peers as soon as their sessions or subscriptions
+// stop being active on the chain. While there is no subscription the statuses
+// are polled instead, backing off the longer the subscription stays down, and
+// once more after subscribing for the events missed in the meantime.
+func (n *Node) jobWatchEvents() {
+	n.Log().Info("Starting a job", "name", "watch_events")
+
+	interval := minPollingInterval
+	for {
+		events, err := n.Client().SubscribeEvents(context.TODO(), n.eventQueries()...)
+		if err != nil {
+			n.Log().Error("failed to subscribe to the events", "error", err)
+		} else {
+			interval = minPollingInterval
+
+			n.pollSessions()
+			for items := range events {
+				n.handleEvents(items)
+			}
+
+			n.Log().Error("Lost the event subscription, polling the sessions")
+		}
+
+		time.Sleep(interval)
+		n.pollSessions()
+
+		if interval *= 2; interval > maxPollingInterval {
+			interval = maxPollingInterval
+		}
+	}
+}
+
+// parseStatus returns the status of the name as encoded by jsonpb, such as
+// STATUS_INACTIVE_PENDING.
+func parseStatus(v string) (hubtypes.Status, bool) {
+	i, ok := hubtypes.Status_value[v]
+	return hubtypes.Status(i), ok
+}
+
+// parseEvents splits the attribute values of the events of the given type,
+// which are listed in the order the events were emitted, into an attribute
+// map per event. The values of the typed events are JSON encoded.
+func parseEvents(items map[string][]string, t string) (result []map[string]string) {
+	prefix := t + "."
+	for key, values := range items {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		for i, value := range values {
+			if i == len(result) {
+				result = append(result, make(map[string]string))
+			}
+
+			var v string
+			if err := json.Unmarshal([]byte(value), &v); err != nil {
+				v = value
+			}
+
+			result[i][strings.TrimPrefix(key, prefix)] = v
+		}
+	}
+
+	return result
+}
+
+func (n *Node) handleEvents(items map[string][]string) {
+	for _, event := range parseEvents(items, sessionEventType) {
+		id, err := strconv.ParseUint(event["id"], 10, 64)
+		if err != nil || id == 0 || event["node_address"] != n.Address().String() {
+			continue
+		}
+
+		status, ok := parseStatus(event["status"])
+		if !ok || status.Equal(hubtypes.StatusActive) {
+			continue
+		}
+
+		n.Log().Info("Session status updated", "id", id, "status", status)
+		n.removeSessionPeers(&types.Session{ID: id})
+	}
+
+	for _, event := range parseEvents(items, subscriptionEventType) {
+		id, err := strconv.ParseUint(event["id"], 10, 64)
+		if err != nil || id == 0 {
+			continue
+		}
+
+		status, ok := parseStatus(event["status"])
+		if !ok || status.Equal(hubtypes.StatusActive) {
+			continue
+		}
+
+		n.removeSessionPeers(&types.Session{Subscription: id})
+	}
+}
+
+// removeSessionPeers removes the peers of the matching sessions, leaving the
+// sessions for the update_sessions job to settle on the chain.
+func (n *Node) removeSessionPeers(query *types.Session) {
+	var items []types.Session
+	n.Database().Model(
+		&types.Session{},
+	).Where(
+		query,
+	).Find(&items)
+
+	for i := 0; i < len(items); i++ {
+		n.Log().Info("Removing the peer of inactive session", "id", items[i].ID)
+		if err := n.RemovePeerIfExists(items[i].Type, items[i].Key); err != nil {
+			n.Log().Error("failed to remove the peer", "error", err, "id", items[i].ID)
+		}
+	}
+}
+
+// pollSessions queries the statuses of the sessions whose peers exist along
+// with their subscriptions.
+func (n *Node) pollSessions() {
+	var items []types.Session
+	n.Database().Model(
+		&types.Session{},
+	).Find(&items)
+
+	// The devices of an account share the subscription, which is queried once.
+	subscriptions := make(map[uint64]bool)
+
+	for i := 0; i < len(items); i++ {
+		ok, err := n.HasPeer(items[i].Type, items[i].Key)
+		if err != nil || !ok {
+			continue
+		}
+
+		session, err := n.Client().QuerySession(items[i].ID)
+		if err != nil {
+			n.Log().Error("failed to query the session", "error", err, "id", items[i].ID)
+			return
+		}
+
+		active := session != nil && session.Status.Equal(hubtypes.StatusActive)
+		if active {
+			ok, found := subscriptions[session.SubscriptionID]
+			if !found {
+				subscription, err := n.Client().QuerySubscription(session.SubscriptionID)
+				if err != nil {
+					n.Log().Error("failed to query the subscription", "error", err, "id", session.SubscriptionID)
+					return
+				}
+
+				ok = subscription != nil && subscription.GetStatus().Equal(hubtypes.StatusActive)
+				subscriptions[session.SubscriptionID] = ok
+			}
+
+			active = ok
+		}
+
+		if !active {
+			n.removeSessionPeers(&types.Session{ID: items[i].ID})
+		}
+	}
+}
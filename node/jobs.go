@@ -11,76 +11,153 @@ import (
 	"github.com/sentinel-official/dvpn-node/types"
 )
 
+// resetTicker resets the ticker of a job once its interval is reloaded.
+func (n *Node) resetTicker(t *time.Ticker, interval, v time.Duration) time.Duration {
+	if v != interval {
+		n.Log().Info("Resetting the job interval", "old", interval, "new", v)
+		t.Reset(v)
+	}
+
+	return v
+}
+
 func (n *Node) jobSetSessions() error {
 	n.Log().Info("Starting a job", "name", "set_sessions", "interval", n.IntervalSetSessions())
 
-	t := time.NewTicker(n.IntervalSetSessions())
+	interval := n.IntervalSetSessions()
+	t := time.NewTicker(interval)
 	for ; ; <-t.C {
-		peers, err := n.Service().Peers()
-		if err != nil {
-			return err
+		interval = n.resetTicker(t, interval, n.IntervalSetSessions())
+		for _, service := range n.Services().Items() {
+			if err := n.setSessions(service); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (n *Node) setSessions(service types.Service) error {
+	peers, err := service.Peers()
+	if err != nil {
+		return err
+	}
+
+	count := len(peers)
+	n.Log().Debug("Validating the peers", "type", service.Type(), "count", count)
+
+	for i := 0; i < count; i++ {
+		var item types.Session
+		n.Database().Model(
+			&types.Session{},
+		).Where(
+			&types.Session{
+				Key: peers[i].Key,
+			},
+		).First(&item)
+
+		if item.ID == 0 {
+			n.Log().Info("Unknown connected peer", "type", service.Type())
+			if err = n.RemovePeer(service.Type(), peers[i].Key); err != nil {
+				return err
+			}
+
+			continue
 		}
+		var (
+			upload   = item.BaseUpload + peers[i].Upload
+			download = item.BaseDownload + peers[i].Download
+		)
 
-		count := len(peers)
-		n.Log().Debug("Validating the peers", "count", count)
-
-		for i := 0; i < count; i++ {
-			var item types.Session
-			n.Database().Model(
-				&types.Session{},
-			).Where(
-				&types.Session{
-					Key: peers[i].Key,
-				},
-			).First(&item)
-
-			if item.ID == 0 {
-				n.Log().Info("Unknown connected peer", "key", peers[i].Key)
-				if err = n.RemovePeer(peers[i].Key); err != nil {
+		// The idle peers are removed to free the capacity, while their
+		// sessions are kept so the clients can connect again.
+		if timeout := n.Config().QOS.IdleTimeout; timeout > 0 {
+			lastActivity := peers[i].LastActivity
+			if item.UpdatedAt.After(lastActivity) {
+				lastActivity = item.UpdatedAt
+			}
+
+			if time.Since(lastActivity) > timeout {
+				n.Log().Info("Idle peer", "id", item.ID, "last_activity", lastActivity)
+				if err = n.RemovePeer(service.Type(), item.Key); err != nil {
 					return err
 				}
 
 				continue
 			}
-			if item.Upload == peers[i].Upload {
-				n.Log().Debug("The peer has not sent any data", "key", item.Key,
-					"update_at", item.UpdatedAt)
-				continue
-			}
+		}
 
-			n.Database().Model(
-				&types.Session{},
-			).Where(
-				&types.Session{
-					ID: item.ID,
-				},
-			).Updates(
-				&types.Session{
-					Upload:   peers[i].Upload,
-					Download: peers[i].Download,
-				},
-			)
+		if item.Upload == upload {
+			n.Log().Debug("The peer has not sent any data", "id", item.ID,
+				"update_at", item.UpdatedAt)
+			continue
+		}
 
-			var (
-				available = sdk.NewInt(item.Available)
-				consumed  = sdk.NewInt(peers[i].Upload + peers[i].Download)
-			)
+		n.Database().Model(
+			&types.Session{},
+		).Where(
+			&types.Session{
+				ID: item.ID,
+			},
+		).Updates(
+			&types.Session{
+				Upload:   upload,
+				Download: download,
+			},
+		)
 
-			if available.IsPositive() && consumed.GT(available) {
-				n.Log().Info("Peer allocation exceeded", "key", item.Key)
-				if err = n.RemovePeer(item.Key); err != nil {
-					return err
-				}
+		if item.Available <= 0 {
+			continue
+		}
+
+		// The devices of the account share the allocation, so the usage of
+		// all its sessions since this one was added counts against it.
+		var total int64
+		n.Database().Model(
+			&types.Session{},
+		).Where(
+			&types.Session{
+				Subscription: item.Subscription,
+				Address:      item.Address,
+			},
+		).Select("COALESCE(SUM(upload + download), 0)").Scan(&total)
+
+		var (
+			available = sdk.NewInt(item.Available)
+			consumed  = sdk.NewInt(total - item.Utilised)
+		)
+
+		if consumed.GT(available) {
+			n.Log().Info("Peer allocation exceeded", "id", item.ID)
+			if err = n.RemovePeer(service.Type(), item.Key); err != nil {
+				return err
 			}
 		}
 	}
+
+	return nil
+}
+
+// jobUpdatePlans waits for the first tick, the plans being fetched during the
+// initialization, and keeps the cached plans when a query fails.
+func (n *Node) jobUpdatePlans() {
+	n.Log().Info("Starting a job", "name", "update_plans", "interval", n.IntervalUpdatePlans())
+
+	interval := n.IntervalUpdatePlans()
+	t := time.NewTicker(interval)
+	for {
+		<-t.C
+		interval = n.resetTicker(t, interval, n.IntervalUpdatePlans())
+		_ = n.UpdatePlans()
+	}
 }
 
 func (n *Node) jobUpdateStatus() error {
 	n.Log().Info("Starting a job", "name", "update_status", "interval", n.IntervalUpdateStatus())
 
-	t := time.NewTicker(n.IntervalUpdateStatus())
+	interval := n.IntervalUpdateStatus()
+	t := time.NewTicker(interval)
 	for ; ; <-t.C {
+		interval = n.resetTicker(t, interval, n.IntervalUpdateStatus())
 		if err := n.UpdateNodeStatus(); err != nil {
 			return err
 		}
@@ -90,8 +167,10 @@ func (n *Node) jobUpdateStatus() error {
 func (n *Node) jobUpdateSessions() error {
 	n.Log().Info("Starting a job", "name", "update_sessions", "interval", n.IntervalUpdateSessions())
 
-	t := time.NewTicker(n.IntervalUpdateSessions())
+	interval := n.IntervalUpdateSessions()
+	t := time.NewTicker(interval)
 	for ; ; <-t.C {
+		interval = n.resetTicker(t, interval, n.IntervalUpdateSessions())
 		var items []types.Session
 		n.Database().Model(
 			&types.Session{},
@@ -139,7 +218,7 @@ func (n *Node) jobUpdateSessions() error {
 					removePeer = true
 				}
 
-				n.Log().Info("Stale peer connection", "key", items[i].Key,
+				n.Log().Info("Stale peer connection", "id", items[i].ID,
 					"created_at", items[i].CreatedAt, "status_at", session.StatusAt)
 			}
 			if !subscription.GetStatus().Equal(hubtypes.StatusActive) {
@@ -148,8 +227,8 @@ func (n *Node) jobUpdateSessions() error {
 					removeSession, skipUpdate = true, true
 				}
 
-				n.Log().Info("Invalid subscription status", "key", items[i].Key,
-					"id", subscription.GetID(), "status", subscription.GetStatus())
+				n.Log().Info("Invalid subscription status", "id", items[i].ID,
+					"subscription", subscription.GetID(), "status", subscription.GetStatus())
 			}
 			if !session.Status.Equal(hubtypes.StatusActive) {
 				removePeer = true
@@ -157,12 +236,11 @@ func (n *Node) jobUpdateSessions() error {
 					removeSession, skipUpdate = true, true
 				}
 
-				n.Log().Info("Invalid session status", "key", items[i].Key,
-					"id", session.ID, "status", session.Status)
+				n.Log().Info("Invalid session status", "id", session.ID, "status", session.Status)
 			}
 
 			if removePeer {
-				if err = n.RemovePeerIfExists(items[i].Key); err != nil {
+				if err = n.RemovePeerIfExists(items[i].Type, items[i].Key); err != nil {
 					return err
 				}
 			}
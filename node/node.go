@@ -24,10 +24,19 @@ func (n *Node) Initialize() error {
 	}
 
 	if result == nil {
-		return n.RegisterNode()
+		if err = n.RegisterNode(); err != nil {
+			return err
+		}
+	} else {
+		if err = n.UpdateNodeInfo(); err != nil {
+			return err
+		}
 	}
 
-	return n.UpdateNodeInfo()
+	// The plans are fetched ahead of the first session, but the store is
+	// queried on a cache miss anyway, so a failure here is not fatal.
+	_ = n.UpdatePlans()
+	return nil
 }
 
 func (n *Node) Start(home string) error {
@@ -43,6 +52,9 @@ func (n *Node) Start(home string) error {
 		}
 	}()
 
+	go n.jobUpdatePlans()
+	go n.jobWatchEvents()
+
 	go func() {
 		if err := n.jobUpdateStatus(); err != nil {
 			panic(err)
@@ -42,7 +42,7 @@ function cmd_help {
 
 function cmd_init {
   NODE_TYPE=wireguard
-  mapfile -t PORTS < <(shuf -i 1024-65535 -n 2)
+  mapfile -t PORTS < <(shuf -i 1024-65535 -n 4)
 
   function run {
     docker run \
@@ -65,6 +65,16 @@ function cmd_init {
     must_run config set "${1}" "${2}"
   }
 
+  function openvpn_config_set {
+    echo "Setting the OpenVPN configuration key=${1}, value=${2}"
+    must_run openvpn config set "${1}" "${2}"
+  }
+
+  function proxy_config_set {
+    echo "Setting the proxy configuration key=${1}, value=${2}"
+    must_run proxy config set "${1}" "${2}"
+  }
+
   function v2ray_config_set {
     echo "Setting the V2Ray configuration key=${1}, value=${2}"
     must_run v2ray config set "${1}" "${2}"
@@ -232,7 +242,8 @@ function cmd_init {
       esac
     }
 
-    local listen_port=${PORTS[1]}
+    local listen_port=${PORTS[2]}
+    local mode=kernel
 
     echo "Initializing the WireGuard configuration..."
     must_run wireguard config init --force="${force}"
@@ -240,6 +251,77 @@ function cmd_init {
     read -p "Enter listen_port [${listen_port}]:" -r input
     [[ -n "${input}" ]] && listen_port="${input}"
     wireguard_config_set "listen_port" "${listen_port}"
+
+    read -p "Enter mode [${mode}]:" -r input
+    [[ -n "${input}" ]] && mode="${input}"
+    wireguard_config_set "mode" "${mode}"
+  }
+
+  function cmd_init_openvpn {
+    function cmd_help {
+      echo "Usage: ${0} init openvpn COMMAND OPTIONS"
+      echo ""
+      echo "Commands:"
+      echo "  help    Print the help message"
+      echo ""
+      echo "Options:"
+      echo "  -f, --force    Force the initialization"
+    }
+
+    local force=0
+
+    [[ "${#}" -gt 0 ]] && {
+      case "${1}" in
+        "-f" | "--force") force=1 ;;
+        "help") cmd_help && return 0 ;;
+        *) echo "Error: invalid command or option \"${1}\"" && return 1 ;;
+      esac
+    }
+
+    local listen_port=443
+    local transport=tcp
+
+    echo "Initializing the OpenVPN configuration..."
+    must_run openvpn config init --force="${force}"
+
+    read -p "Enter listen_port [${listen_port}]:" -r input
+    [[ -n "${input}" ]] && listen_port="${input}"
+    openvpn_config_set "listen_port" "${listen_port}"
+
+    read -p "Enter transport [${transport}]:" -r input
+    [[ -n "${input}" ]] && transport="${input}"
+    openvpn_config_set "transport" "${transport}"
+  }
+
+  function cmd_init_proxy {
+    function cmd_help {
+      echo "Usage: ${0} init proxy COMMAND OPTIONS"
+      echo ""
+      echo "Commands:"
+      echo "  help    Print the help message"
+      echo ""
+      echo "Options:"
+      echo "  -f, --force    Force the initialization"
+    }
+
+    local force=0
+
+    [[ "${#}" -gt 0 ]] && {
+      case "${1}" in
+        "-f" | "--force") force=1 ;;
+        "help") cmd_help && return 0 ;;
+        *) echo "Error: invalid command or option \"${1}\"" && return 1 ;;
+      esac
+    }
+
+    local listen_port=${PORTS[3]}
+
+    echo "Initializing the proxy configuration..."
+    must_run proxy config init --force="${force}"
+
+    read -p "Enter listen_port [${listen_port}]:" -r input
+    [[ -n "${input}" ]] && listen_port="${input}"
+    proxy_config_set "listen_port" "${listen_port}"
   }
 
   function cmd_init_all {
@@ -262,8 +344,10 @@ function cmd_init {
     }
 
     cmd_init_config "${@}"
-    [[ "${NODE_TYPE}" == "v2ray" ]] && cmd_init_v2ray "${@}"
-    [[ "${NODE_TYPE}" == "wireguard" ]] && cmd_init_wireguard "${@}"
+    [[ ",${NODE_TYPE// /}," == *",v2ray,"* ]] && cmd_init_v2ray "${@}"
+    [[ ",${NODE_TYPE// /}," == *",wireguard,"* ]] && cmd_init_wireguard "${@}"
+    [[ ",${NODE_TYPE// /}," == *",openvpn,"* ]] && cmd_init_openvpn "${@}"
+    [[ ",${NODE_TYPE// /}," == *",proxy,"* ]] && cmd_init_proxy "${@}"
     cmd_init_keys "${@}"
   }
 
@@ -275,12 +359,14 @@ function cmd_init {
     echo "  config       Initialize the config.toml file"
     echo "  help         Print the help message"
     echo "  keys         Initialize the keys"
+    echo "  openvpn      Initialize the openvpn.toml file"
+    echo "  proxy        Initialize the proxy.toml file"
     echo "  v2ray        Initialize the v2ray.toml file"
     echo "  wireguard    Initialize the wireguard.toml file"
   }
 
   v="${1:-help}" && case "${v}" in
-    "all" | "config" | "help" | "keys" | "v2ray" | "wireguard")
+    "all" | "config" | "help" | "keys" | "openvpn" | "proxy" | "v2ray" | "wireguard")
       shift || true
       cmd_init_"${v}" "${@}"
       ;;
@@ -379,42 +465,74 @@ function cmd_start {
   node_api_port=$(awk -F '[=":]' '{gsub(/ /,"")} /\[node\]/{f=1} f && /listen_on/{print $4;exit}' "${NODE_DIR}/config.toml")
   node_type=$(awk -F '[="]' '{gsub(/ /,"")} /\[node\]/{f=1} f && /type/{print $3;exit}' "${NODE_DIR}/config.toml")
 
-  if [[ "${node_type}" == "v2ray" ]]; then
+  local args=(--publish "${node_api_port}:${node_api_port}/tcp")
+
+  if [[ ",${node_type}," == *",v2ray,"* ]]; then
     vmess_port=$(awk -F '=' '{gsub(/ /,"")} /\[vmess\]/{f=1} f && /listen_port/{print $2;exit}' "${NODE_DIR}/v2ray.toml")
-    docker run \
-      --detach="${detach}" \
-      --interactive \
-      --name="${CONTAINER_NAME}" \
-      --rm="${rm}" \
-      --tty \
-      --volume "${NODE_DIR}:/root/.sentinelnode" \
-      --publish "${node_api_port}:${node_api_port}/tcp" \
-      --publish "${vmess_port}:${vmess_port}/tcp" \
-      "${NODE_IMAGE}" process start
+    mapfile -t vmess_inbound_ports < <(awk -F '=' '{gsub(/ /,"")} /^\[\[vmess\.inbounds\]\]/{f=1} f && /^listen_port/{print $2;f=0}' "${NODE_DIR}/v2ray.toml")
+    vless_enable=$(awk -F '=' '{gsub(/ /,"")} /\[vless\]/{f=1} f && /enable/{print $2;exit}' "${NODE_DIR}/v2ray.toml")
+    vless_port=$(awk -F '=' '{gsub(/ /,"")} /\[vless\]/{f=1} f && /listen_port/{print $2;exit}' "${NODE_DIR}/v2ray.toml")
+    trojan_enable=$(awk -F '=' '{gsub(/ /,"")} /\[trojan\]/{f=1} f && /enable/{print $2;exit}' "${NODE_DIR}/v2ray.toml")
+    trojan_port=$(awk -F '=' '{gsub(/ /,"")} /\[trojan\]/{f=1} f && /listen_port/{print $2;exit}' "${NODE_DIR}/v2ray.toml")
+    shadowsocks_enable=$(awk -F '=' '{gsub(/ /,"")} /\[shadowsocks\]/{f=1} f && /enable/{print $2;exit}' "${NODE_DIR}/v2ray.toml")
+    shadowsocks_min_port=$(awk -F '=' '{gsub(/ /,"")} /\[shadowsocks\]/{f=1} f && /min_port/{print $2;exit}' "${NODE_DIR}/v2ray.toml")
+    shadowsocks_max_port=$(awk -F '=' '{gsub(/ /,"")} /\[shadowsocks\]/{f=1} f && /max_port/{print $2;exit}' "${NODE_DIR}/v2ray.toml")
+
+    args+=(--publish "${vmess_port}:${vmess_port}/tcp")
+    for port in "${vmess_inbound_ports[@]}"; do
+      args+=(--publish "${port}:${port}/tcp")
+    done
+    [[ "${vless_enable}" == "true" ]] && args+=(--publish "${vless_port}:${vless_port}/tcp")
+    [[ "${trojan_enable}" == "true" ]] && args+=(--publish "${trojan_port}:${trojan_port}/tcp")
+    [[ "${shadowsocks_enable}" == "true" ]] && args+=(
+      --publish "${shadowsocks_min_port}-${shadowsocks_max_port}:${shadowsocks_min_port}-${shadowsocks_max_port}/tcp"
+      --publish "${shadowsocks_min_port}-${shadowsocks_max_port}:${shadowsocks_min_port}-${shadowsocks_max_port}/udp"
+    )
+  fi
+  if [[ ",${node_type}," == *",proxy,"* ]]; then
+    port=$(awk -F '=' '{gsub(/ /,"")} /listen_port/{print $2;exit}' "${NODE_DIR}/proxy.toml")
+    args+=(--publish "${port}:${port}/tcp")
   fi
-  if [[ "${node_type}" == "wireguard" ]]; then
+  if [[ ",${node_type}," == *",wireguard,"* || ",${node_type}," == *",openvpn,"* ]]; then
+    args+=(
+      --cap-drop ALL
+      --cap-add NET_ADMIN
+      --cap-add NET_BIND_SERVICE
+      --cap-add NET_RAW
+      --sysctl net.ipv4.ip_forward=1
+      --sysctl net.ipv6.conf.all.disable_ipv6=0
+      --sysctl net.ipv6.conf.all.forwarding=1
+      --sysctl net.ipv6.conf.default.forwarding=1
+    )
+  fi
+  if [[ ",${node_type}," == *",wireguard,"* ]]; then
     port=$(awk -F '=' '{gsub(/ /,"")} /listen_port/{print $2;exit}' "${NODE_DIR}/wireguard.toml")
-    docker run \
-      --detach="${detach}" \
-      --interactive \
-      --name="${CONTAINER_NAME}" \
-      --rm="${rm}" \
-      --tty \
-      --volume /lib/modules:/lib/modules \
-      --volume "${NODE_DIR}:/root/.sentinelnode" \
-      --cap-drop ALL \
-      --cap-add NET_ADMIN \
-      --cap-add NET_BIND_SERVICE \
-      --cap-add NET_RAW \
-      --cap-add SYS_MODULE \
-      --sysctl net.ipv4.ip_forward=1 \
-      --sysctl net.ipv6.conf.all.disable_ipv6=0 \
-      --sysctl net.ipv6.conf.all.forwarding=1 \
-      --sysctl net.ipv6.conf.default.forwarding=1 \
-      --publish "${node_api_port}:${node_api_port}/tcp" \
-      --publish "${port}:${port}/udp" \
-      "${NODE_IMAGE}" process start
+    mode=$(awk -F '[="]' '{gsub(/ /,"")} /^mode/{print $3;exit}' "${NODE_DIR}/wireguard.toml")
+    args+=(--publish "${port}:${port}/udp")
+    if [[ "${mode}" == "userspace" ]]; then
+      [[ ",${node_type}," != *",openvpn,"* ]] && args+=(--device /dev/net/tun)
+    else
+      args+=(--volume /lib/modules:/lib/modules --cap-add SYS_MODULE)
+    fi
   fi
+  if [[ ",${node_type}," == *",openvpn,"* ]]; then
+    port=$(awk -F '=' '{gsub(/ /,"")} /listen_port/{print $2;exit}' "${NODE_DIR}/openvpn.toml")
+    transport=$(awk -F '[="]' '{gsub(/ /,"")} /^transport/{print $3;exit}' "${NODE_DIR}/openvpn.toml")
+    args+=(
+      --device /dev/net/tun
+      --publish "${port}:${port}/${transport}"
+    )
+  fi
+
+  docker run \
+    --detach="${detach}" \
+    --interactive \
+    --name="${CONTAINER_NAME}" \
+    --rm="${rm}" \
+    --tty \
+    --volume "${NODE_DIR}:/root/.sentinelnode" \
+    "${args[@]}" \
+    "${NODE_IMAGE}" process start
 }
 
 function cmd_status {
@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "openvpn",
+		Aliases: []string{"ovpn"},
+		Short:   "OpenVPN sub-commands",
+	}
+
+	cmd.AddCommand(
+		configCmd(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,58 @@
+package openvpn
+
+import (
+	"strings"
+)
+
+// nolint:lll
+var (
+	configTemplate = strings.TrimSpace(`
+dev {{ .Interface }}
+dev-type tun
+proto {{ .Transport }}
+port {{ .ListenPort }}
+topology subnet
+server 10.9.0.0 255.255.0.0
+server-ipv6 fd86:ea04:1116::/64
+ca {{ .CACertPath }}
+cert {{ .ServerCertPath }}
+key {{ .ServerKeyPath }}
+dh none
+data-ciphers {{ .Cipher }}
+verify-client-cert none
+username-as-common-name
+management {{ .ManagementPath }} unix
+management-client-auth
+keepalive 10 60
+push "redirect-gateway def1 ipv6 bypass-dhcp"
+push "dhcp-option DNS 1.1.1.1"
+push "dhcp-option DNS 1.0.0.1"
+{{- if eq .Transport "udp" }}
+explicit-exit-notify 1
+{{- end }}
+verb 3
+    `)
+)
+
+// nolint:lll
+var (
+	clientConfigTemplate = strings.TrimSpace(`
+client
+dev tun
+proto {{ .Transport }}
+remote {{ .Host }} {{ .Port }}
+nobind
+persist-key
+persist-tun
+remote-cert-tls server
+data-ciphers {{ .Cipher }}
+<auth-user-pass>
+{{ .Username }}
+{{ .Password }}
+</auth-user-pass>
+<ca>
+{{ .CACert }}
+</ca>
+verb 3
+    `)
+)
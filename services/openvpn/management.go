@@ -0,0 +1,230 @@
+package openvpn
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	callTimeout = 10 * time.Second
+)
+
+// event is a real-time >CLIENT notification along with its environment.
+type event struct {
+	kind string
+	cid  uint64
+	kid  uint64
+	env  map[string]string
+}
+
+// management is a client of the OpenVPN management interface. Command
+// responses and real-time notifications share the connection, so a single
+// reader demultiplexes them; notifications are handed to onEvent from a
+// separate goroutine, which is free to issue commands.
+type management struct {
+	sync.Mutex
+	conn    net.Conn
+	lines   chan string
+	events  chan *event
+	done    chan struct{}
+	onEvent func(*management, *event)
+	onClose func()
+}
+
+func dialManagement(path string, timeout time.Duration, onEvent func(*management, *event), onClose func()) (*management, error) {
+	var (
+		conn     net.Conn
+		err      error
+		deadline = time.Now().Add(timeout)
+	)
+
+	for {
+		conn, err = net.Dial("unix", path)
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.Wrap(err, "failed to connect to the management interface")
+		}
+
+		time.Sleep(250 * time.Millisecond)
+	}
+
+	m := &management{
+		conn:    conn,
+		lines:   make(chan string, 256),
+		events:  make(chan *event, 256),
+		done:    make(chan struct{}),
+		onEvent: onEvent,
+		onClose: onClose,
+	}
+
+	go m.read()
+	go m.dispatch()
+
+	return m, nil
+}
+
+func (m *management) read() {
+	defer func() {
+		close(m.lines)
+		close(m.events)
+		close(m.done)
+
+		if m.onClose != nil {
+			m.onClose()
+		}
+	}()
+
+	var (
+		current *event
+		scanner = bufio.NewScanner(m.conn)
+	)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if !strings.HasPrefix(line, ">") {
+			m.lines <- line
+			continue
+		}
+
+		kind, value, _ := strings.Cut(line[1:], ":")
+		if kind != "CLIENT" {
+			continue
+		}
+
+		name, args, _ := strings.Cut(value, ",")
+		if name == "ENV" {
+			if current == nil {
+				continue
+			}
+			if args == "END" {
+				m.events <- current
+				current = nil
+				continue
+			}
+
+			k, v, _ := strings.Cut(args, "=")
+			current.env[k] = v
+			continue
+		}
+
+		// ADDRESS notifications carry no environment block.
+		if name == "ADDRESS" {
+			continue
+		}
+
+		fields := strings.Split(args, ",")
+		current = &event{
+			kind: name,
+			env:  make(map[string]string),
+		}
+
+		if len(fields) > 0 {
+			current.cid, _ = strconv.ParseUint(fields[0], 10, 64)
+		}
+		if len(fields) > 1 {
+			current.kid, _ = strconv.ParseUint(fields[1], 10, 64)
+		}
+	}
+}
+
+func (m *management) dispatch() {
+	for e := range m.events {
+		if m.onEvent != nil {
+			m.onEvent(m, e)
+		}
+	}
+}
+
+func (m *management) next() (string, error) {
+	select {
+	case line, ok := <-m.lines:
+		if !ok {
+			return "", errors.New("management interface connection closed")
+		}
+
+		return line, nil
+	case <-time.After(callTimeout):
+		return "", errors.New("management interface call timed out")
+	}
+}
+
+func (m *management) send(command string) error {
+	// Discard the leftovers of a command that previously timed out.
+	for len(m.lines) > 0 {
+		<-m.lines
+	}
+
+	if err := m.conn.SetWriteDeadline(time.Now().Add(callTimeout)); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(m.conn, "%s\n", command)
+	return err
+}
+
+// Command issues a command that is answered with a single SUCCESS or ERROR line.
+func (m *management) Command(command string) (string, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if err := m.send(command); err != nil {
+		return "", err
+	}
+
+	for {
+		line, err := m.next()
+		if err != nil {
+			return "", err
+		}
+
+		if v, ok := strings.CutPrefix(line, "SUCCESS:"); ok {
+			return strings.TrimSpace(v), nil
+		}
+		if v, ok := strings.CutPrefix(line, "ERROR:"); ok {
+			return "", fmt.Errorf("management command %q failed: %s", command, strings.TrimSpace(v))
+		}
+	}
+}
+
+// MultiLineCommand issues a command whose response is terminated by an END line.
+func (m *management) MultiLineCommand(command string) ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if err := m.send(command); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for {
+		line, err := m.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if line == "END" {
+			return lines, nil
+		}
+		if v, ok := strings.CutPrefix(line, "ERROR:"); ok {
+			return nil, fmt.Errorf("management command %q failed: %s", command, strings.TrimSpace(v))
+		}
+
+		lines = append(lines, line)
+	}
+}
+
+func (m *management) Close() error {
+	err := m.conn.Close()
+	<-m.done
+
+	return err
+}
@@ -0,0 +1,669 @@
+package openvpn
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	ovpntypes "github.com/sentinel-official/dvpn-node/services/openvpn/types"
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+const (
+	InfoLen = 2 + 1
+
+	ipv4Subnet = "10.9.0.0/16"
+	ipv6Subnet = "fd86:ea04:1116::/64"
+
+	minRestartBackoff = 1 * time.Second
+	maxRestartBackoff = 1 * time.Minute
+)
+
+var (
+	_ types.Service = (*OpenVPN)(nil)
+)
+
+type traffic struct {
+	upload   int64
+	download int64
+}
+
+type OpenVPN struct {
+	sync.RWMutex
+	info   []byte
+	caCert []byte
+	config *ovpntypes.Config
+	peers  *ovpntypes.Peers
+
+	cmd  *exec.Cmd
+	mgmt *management
+	log  tmlog.Logger
+
+	alive    bool
+	stopped  bool
+	restarts uint64
+
+	// clients maps the client IDs of the established connections to the
+	// identities they authenticated with; live holds their counters, while
+	// base accumulates the counters of the closed connections per identity.
+	// seen holds the time an identity was added or last disconnected.
+	clients map[uint64]string
+	live    map[uint64]traffic
+	base    map[string]traffic
+	seen    map[string]time.Time
+}
+
+func NewOpenVPN(log tmlog.Logger) types.Service {
+	return &OpenVPN{
+		log:     log.With("service", "openvpn"),
+		info:    make([]byte, InfoLen),
+		config:  ovpntypes.NewConfig(),
+		peers:   ovpntypes.NewPeers(),
+		clients: make(map[uint64]string),
+		live:    make(map[uint64]traffic),
+		base:    make(map[string]traffic),
+		seen:    make(map[string]time.Time),
+	}
+}
+
+func (s *OpenVPN) configFilePath() string {
+	return filepath.Join(os.TempDir(), "openvpn_server.conf")
+}
+
+func (s *OpenVPN) managementPath() string {
+	return filepath.Join(os.TempDir(), "openvpn_management.sock")
+}
+
+func (s *OpenVPN) Type() uint64 {
+	return ovpntypes.Type
+}
+
+func (s *OpenVPN) Init(home string) (err error) {
+	v := viper.New()
+	v.SetConfigFile(filepath.Join(home, ovpntypes.ConfigFileName))
+
+	s.config, err = ovpntypes.ReadInConfig(v)
+	if err != nil {
+		return err
+	}
+	if err = s.config.Validate(); err != nil {
+		return err
+	}
+
+	s.caCert, err = loadOrCreatePKI(home)
+	if err != nil {
+		return err
+	}
+
+	t, err := template.New("openvpn_conf").Parse(configTemplate)
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	err = t.Execute(&buffer, map[string]interface{}{
+		"CACertPath":     filepath.Join(home, ovpntypes.CACertFileName),
+		"Cipher":         s.config.Cipher,
+		"Interface":      s.config.Interface,
+		"ListenPort":     s.config.ListenPort,
+		"ManagementPath": s.managementPath(),
+		"ServerCertPath": filepath.Join(home, ovpntypes.ServerCertFileName),
+		"ServerKeyPath":  filepath.Join(home, ovpntypes.ServerKeyFileName),
+		"Transport":      s.config.Transport,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = os.WriteFile(s.configFilePath(), buffer.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint16(s.info[:2], s.config.ListenPort)
+	if s.config.Transport == ovpntypes.TransportUDP {
+		s.info[2] = 1
+	}
+
+	return nil
+}
+
+func (s *OpenVPN) Info() []byte {
+	return s.info
+}
+
+func (s *OpenVPN) iptables(action string) error {
+	commands := [][]string{
+		{"iptables", action, "FORWARD", "-i", s.config.Interface, "-j", "ACCEPT"},
+		{"iptables", "-t", "nat", action, "POSTROUTING", "-s", ipv4Subnet, "-o", "eth0", "-j", "MASQUERADE"},
+		{"ip6tables", action, "FORWARD", "-i", s.config.Interface, "-j", "ACCEPT"},
+		{"ip6tables", "-t", "nat", action, "POSTROUTING", "-s", ipv6Subnet, "-o", "eth0", "-j", "MASQUERADE"},
+	}
+
+	for _, args := range commands {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *OpenVPN) start() error {
+	_ = os.Remove(s.managementPath())
+
+	cmd := exec.Command("openvpn", "--config", s.configFilePath())
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	kill := func() {
+		_ = cmd.Process.Kill()
+	}
+
+	// A lost management connection leaves the new clients unauthenticated,
+	// so the process is killed and restarted by the supervisor instead.
+	mgmt, err := dialManagement(s.managementPath(), callTimeout, s.handle, kill)
+	if err != nil {
+		kill()
+		_ = cmd.Wait()
+		return err
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.stopped {
+		kill()
+		_ = mgmt.Close()
+		_ = cmd.Wait()
+		return errors.New("openvpn process has been stopped")
+	}
+
+	s.cmd = cmd
+	s.mgmt = mgmt
+	s.alive = true
+
+	return nil
+}
+
+func (s *OpenVPN) Start() error {
+	if err := s.iptables("-A"); err != nil {
+		return err
+	}
+	if err := s.start(); err != nil {
+		return err
+	}
+
+	go s.supervise()
+	return nil
+}
+
+func (s *OpenVPN) Stop() error {
+	s.Lock()
+	if s.cmd == nil {
+		s.Unlock()
+		return errors.New("command is nil")
+	}
+
+	s.stopped = true
+	s.alive = false
+
+	cmd, mgmt := s.cmd, s.mgmt
+	s.Unlock()
+
+	if mgmt != nil {
+		_ = mgmt.Close()
+	}
+	if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+
+	return s.iptables("-D")
+}
+
+func (s *OpenVPN) isStopped() bool {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.stopped
+}
+
+func (s *OpenVPN) supervise() {
+	backoff := minRestartBackoff
+	for {
+		s.RLock()
+		cmd := s.cmd
+		s.RUnlock()
+
+		startedAt := time.Now()
+		err := cmd.Wait()
+
+		s.Lock()
+		s.alive = false
+		mgmt := s.mgmt
+		s.mgmt = nil
+
+		for cid, v := range s.live {
+			t := s.base[s.clients[cid]]
+			t.upload += v.upload
+			t.download += v.download
+			s.base[s.clients[cid]] = t
+		}
+		for _, identity := range s.clients {
+			if _, ok := s.seen[identity]; ok {
+				s.seen[identity] = time.Now()
+			}
+		}
+
+		s.clients = make(map[uint64]string)
+		s.live = make(map[uint64]traffic)
+		s.Unlock()
+
+		if mgmt != nil {
+			_ = mgmt.Close()
+		}
+		if s.isStopped() {
+			return
+		}
+
+		s.log.Error("The OpenVPN process exited", "error", err)
+		if time.Since(startedAt) > maxRestartBackoff {
+			backoff = minRestartBackoff
+		}
+
+		for {
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > maxRestartBackoff {
+				backoff = maxRestartBackoff
+			}
+
+			if s.isStopped() {
+				return
+			}
+
+			s.Lock()
+			s.restarts++
+			s.Unlock()
+
+			s.log.Info("Restarting the OpenVPN process", "backoff", backoff)
+			if err = s.start(); err != nil {
+				s.log.Error("Failed to restart the OpenVPN process", "error", err)
+				continue
+			}
+
+			break
+		}
+	}
+}
+
+func (s *OpenVPN) command(command string) error {
+	s.RLock()
+	mgmt := s.mgmt
+	s.RUnlock()
+
+	if mgmt == nil {
+		return errors.New("openvpn process is not running")
+	}
+
+	_, err := mgmt.Command(command)
+	return err
+}
+
+// handle authenticates the connecting clients against the added peers and
+// keeps track of the established connections and their final counters.
+func (s *OpenVPN) handle(m *management, e *event) {
+	identity := e.env["username"]
+	if identity == "" {
+		identity = e.env["common_name"]
+	}
+
+	switch e.kind {
+	case "CONNECT", "REAUTH":
+		var (
+			peer    = s.peers.Get(identity)
+			command = fmt.Sprintf("client-auth-nt %d %d", e.cid, e.kid)
+		)
+
+		if peer.Empty() || subtle.ConstantTimeCompare([]byte(peer.Password), []byte(e.env["password"])) != 1 {
+			command = fmt.Sprintf(`client-deny %d %d "authentication failed"`, e.cid, e.kid)
+		}
+
+		if _, err := m.Command(command); err != nil {
+			s.log.Error("Failed to authenticate the OpenVPN client", "cid", e.cid, "error", err)
+		}
+	case "ESTABLISHED":
+		s.Lock()
+		s.clients[e.cid] = identity
+		s.Unlock()
+	case "DISCONNECT":
+		upload, _ := strconv.ParseInt(e.env["bytes_received"], 10, 64)
+		download, _ := strconv.ParseInt(e.env["bytes_sent"], 10, 64)
+
+		s.Lock()
+		defer s.Unlock()
+
+		if _, ok := s.clients[e.cid]; !ok {
+			return
+		}
+
+		delete(s.clients, e.cid)
+		delete(s.live, e.cid)
+
+		if s.peers.Get(identity).Empty() {
+			return
+		}
+
+		t := s.base[identity]
+		t.upload += upload
+		t.download += download
+		s.base[identity] = t
+		s.seen[identity] = time.Now()
+	}
+}
+
+func (s *OpenVPN) AddPeer(data []byte) (result []byte, err error) {
+	if len(data) != ovpntypes.KeyLen {
+		return nil, fmt.Errorf("data length must be %d bytes", ovpntypes.KeyLen)
+	}
+
+	identity := ovpntypes.IdentityFromKey(data)
+	s.peers.Put(
+		ovpntypes.Peer{
+			Identity: identity,
+			Key:      base64.StdEncoding.EncodeToString(data),
+			Password: ovpntypes.PasswordFromKey(data),
+		},
+	)
+
+	s.Lock()
+	s.seen[identity] = time.Now()
+	s.Unlock()
+
+	return nil, nil
+}
+
+func (s *OpenVPN) HasPeer(data []byte) bool {
+	var (
+		identity = ovpntypes.IdentityFromKey(data)
+		peer     = s.peers.Get(identity)
+	)
+
+	return !peer.Empty()
+}
+
+func (s *OpenVPN) RemovePeer(data []byte) error {
+	if len(data) != ovpntypes.KeyLen {
+		return fmt.Errorf("data length must be %d bytes", ovpntypes.KeyLen)
+	}
+
+	identity := ovpntypes.IdentityFromKey(data)
+	s.peers.Delete(identity)
+
+	var cids []uint64
+
+	s.Lock()
+	delete(s.base, identity)
+	delete(s.seen, identity)
+	for cid, v := range s.clients {
+		if v == identity {
+			cids = append(cids, cid)
+		}
+	}
+	s.Unlock()
+
+	for _, cid := range cids {
+		if err := s.command(fmt.Sprintf("client-kill %d", cid)); err != nil {
+			s.RLock()
+			_, ok := s.clients[cid]
+			s.RUnlock()
+
+			// The client may have disconnected in the meantime.
+			if ok {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *OpenVPN) RekeyPeer(data, newData []byte) ([]byte, error) {
+	if !s.HasPeer(data) {
+		return nil, fmt.Errorf("peer %s does not exist", base64.StdEncoding.EncodeToString(data))
+	}
+	if s.HasPeer(newData) {
+		return nil, fmt.Errorf("peer %s already exists", base64.StdEncoding.EncodeToString(newData))
+	}
+	if len(newData) != ovpntypes.KeyLen {
+		return nil, fmt.Errorf("data length must be %d bytes", ovpntypes.KeyLen)
+	}
+
+	if err := s.RemovePeer(data); err != nil {
+		return nil, err
+	}
+
+	result, err := s.AddPeer(newData)
+	if err != nil {
+		_, _ = s.AddPeer(data)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// status refreshes the counters of the established connections from the
+// CLIENT_LIST rows of the status command.
+func (s *OpenVPN) status() error {
+	s.RLock()
+	mgmt := s.mgmt
+	s.RUnlock()
+
+	if mgmt == nil {
+		return nil
+	}
+
+	lines, err := mgmt.MultiLineCommand("status 3")
+	if err != nil {
+		return err
+	}
+
+	var columns map[string]int
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+		if len(fields) > 1 && fields[0] == "HEADER" && fields[1] == "CLIENT_LIST" {
+			columns = make(map[string]int)
+			for i, name := range fields[1:] {
+				columns[name] = i
+			}
+		}
+	}
+
+	if columns == nil {
+		return errors.New("client list header not found in the status output")
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+		if fields[0] != "CLIENT_LIST" || len(fields) < len(columns) {
+			continue
+		}
+
+		cid, err := strconv.ParseUint(fields[columns["Client ID"]], 10, 64)
+		if err != nil {
+			return err
+		}
+		if _, ok := s.clients[cid]; !ok {
+			continue
+		}
+
+		upload, err := strconv.ParseInt(fields[columns["Bytes Received"]], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		download, err := strconv.ParseInt(fields[columns["Bytes Sent"]], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		s.live[cid] = traffic{
+			upload:   upload,
+			download: download,
+		}
+	}
+
+	return nil
+}
+
+func (s *OpenVPN) Peers() (items []types.Peer, err error) {
+	// The cached counters are reported while the process is down.
+	if err = s.status(); err != nil && s.Health().Running {
+		return nil, err
+	}
+
+	s.RLock()
+	defer s.RUnlock()
+
+	var (
+		now    = time.Now()
+		totals = make(map[string]traffic)
+		active = make(map[string]bool)
+	)
+
+	for identity, v := range s.base {
+		totals[identity] = v
+	}
+	for cid, v := range s.live {
+		t := totals[s.clients[cid]]
+		t.upload += v.upload
+		t.download += v.download
+		totals[s.clients[cid]] = t
+	}
+	for _, identity := range s.clients {
+		active[identity] = true
+	}
+
+	err = s.peers.Iterate(
+		func(key string, value ovpntypes.Peer) (bool, error) {
+			// The server drops the silent clients on the keepalive timeout,
+			// so an established connection counts as activity.
+			lastActivity := s.seen[key]
+			if active[key] {
+				lastActivity = now
+			}
+
+			t := totals[key]
+			items = append(items,
+				types.Peer{
+					Key:          value.Key,
+					Upload:       t.upload,
+					Download:     t.download,
+					LastActivity: lastActivity,
+				},
+			)
+
+			return false, nil
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (s *OpenVPN) PeerCount() int {
+	return s.peers.Len()
+}
+
+func (s *OpenVPN) Health() types.ServiceHealth {
+	s.RLock()
+	defer s.RUnlock()
+
+	return types.ServiceHealth{
+		Running:  s.alive,
+		Restarts: s.restarts,
+	}
+}
+
+func (s *OpenVPN) Ports() []uint16 {
+	return []uint16{s.config.ListenPort}
+}
+
+func (s *OpenVPN) Endpoints() []types.ServiceEndpoint {
+	return []types.ServiceEndpoint{
+		{
+			Protocol:  "openvpn",
+			Port:      s.config.ListenPort,
+			Transport: s.config.Transport,
+			TLS:       true,
+		},
+	}
+}
+
+func (s *OpenVPN) PeerConfig(host string, data []byte) (*types.PeerConfig, error) {
+	if len(data) != ovpntypes.KeyLen {
+		return nil, fmt.Errorf("data length must be %d bytes", ovpntypes.KeyLen)
+	}
+
+	peer := s.peers.Get(ovpntypes.IdentityFromKey(data))
+	if peer.Empty() {
+		return nil, fmt.Errorf("peer %s does not exist", base64.StdEncoding.EncodeToString(data))
+	}
+
+	config := &types.PeerConfig{
+		Type:      s.Type(),
+		Host:      host,
+		Port:      s.config.ListenPort,
+		Protocol:  "openvpn",
+		Transport: s.config.Transport,
+		TLS:       true,
+	}
+
+	t, err := template.New("openvpn_client_conf").Parse(clientConfigTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	err = t.Execute(&buffer, map[string]interface{}{
+		"CACert":    strings.TrimSpace(string(s.caCert)),
+		"Cipher":    s.config.Cipher,
+		"Host":      host,
+		"Password":  peer.Password,
+		"Port":      s.config.ListenPort,
+		"Transport": s.config.Transport,
+		"Username":  peer.Identity,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	config.Config = buffer.String()
+	return config, nil
+}
@@ -0,0 +1,123 @@
+package openvpn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	ovpntypes "github.com/sentinel-official/dvpn-node/services/openvpn/types"
+)
+
+const (
+	certValidity = 10 * 365 * 24 * time.Hour
+)
+
+func serialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+func encodePEM(t string, b []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: t, Bytes: b})
+}
+
+// loadOrCreatePKI returns the PEM encoded CA certificate stored in home,
+// generating a CA and a server certificate signed by it when either is
+// missing. The CA key is discarded once the server certificate is signed.
+func loadOrCreatePKI(home string) ([]byte, error) {
+	var (
+		caCertPath     = filepath.Join(home, ovpntypes.CACertFileName)
+		serverCertPath = filepath.Join(home, ovpntypes.ServerCertFileName)
+		serverKeyPath  = filepath.Join(home, ovpntypes.ServerKeyFileName)
+	)
+
+	caCertPEM, err := os.ReadFile(caCertPath)
+	if err == nil {
+		_, err = os.Stat(serverCertPath)
+	}
+	if err == nil {
+		_, err = os.Stat(serverKeyPath)
+	}
+	if err == nil {
+		return caCertPEM, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	caSerial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          caSerial,
+		Subject:               pkix.Name{CommonName: "dVPN OpenVPN CA"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	caCertDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	serverKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serverSerial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+
+	serverTemplate := &x509.Certificate{
+		SerialNumber:          serverSerial,
+		Subject:               pkix.Name{CommonName: "server"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyAgreement,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	serverCertDER, err := x509.CreateCertificate(rand.Reader, serverTemplate, caTemplate, &serverKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	serverKeyDER, err := x509.MarshalPKCS8PrivateKey(serverKey)
+	if err != nil {
+		return nil, err
+	}
+
+	caCertPEM = encodePEM("CERTIFICATE", caCertDER)
+	if err = os.WriteFile(caCertPath, caCertPEM, 0644); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(serverCertPath, encodePEM("CERTIFICATE", serverCertDER), 0644); err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(serverKeyPath, encodePEM("PRIVATE KEY", serverKeyDER), 0600); err != nil {
+		return nil, err
+	}
+
+	return caCertPEM, nil
+}
@@ -0,0 +1,145 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+
+	"github.com/sentinel-official/dvpn-node/utils"
+)
+
+var (
+	ct = strings.TrimSpace(`
+# Data channel cipher; AES-128-GCM, AES-256-GCM or CHACHA20-POLY1305
+cipher = "{{ .Cipher }}"
+
+# Name of the network interface
+interface = "{{ .Interface }}"
+
+# Port number to accept the incoming connections
+listen_port = {{ .ListenPort }}
+
+# Transport protocol of the server; tcp or udp
+transport = "{{ .Transport }}"
+	`)
+
+	t = func() *template.Template {
+		t, err := template.New("openvpn_toml").Parse(ct)
+		if err != nil {
+			panic(err)
+		}
+
+		return t
+	}()
+
+	ciphers = []string{"AES-128-GCM", "AES-256-GCM", "CHACHA20-POLY1305"}
+)
+
+type Config struct {
+	Cipher     string `json:"cipher" mapstructure:"cipher"`
+	Interface  string `json:"interface" mapstructure:"interface"`
+	ListenPort uint16 `json:"listen_port" mapstructure:"listen_port"`
+	Transport  string `json:"transport" mapstructure:"transport"`
+}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func (c *Config) Validate() error {
+	if c.Cipher == "" {
+		return errors.New("cipher cannot be empty")
+	}
+
+	valid := false
+	for _, v := range ciphers {
+		if c.Cipher == v {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("cipher must be one of %s", strings.Join(ciphers, ", "))
+	}
+
+	if c.Interface == "" {
+		return errors.New("interface cannot be empty")
+	}
+	if c.ListenPort == 0 {
+		return errors.New("listen_port cannot be zero")
+	}
+	if c.Transport != TransportTCP && c.Transport != TransportUDP {
+		return errors.New("transport must be either tcp or udp")
+	}
+
+	return nil
+}
+
+func (c *Config) WithDefaultValues() *Config {
+	c.Cipher = "AES-256-GCM"
+	c.Interface = "tun0"
+	c.ListenPort = 443
+	c.Transport = TransportTCP
+
+	return c
+}
+
+func (c *Config) SaveToPath(path string) error {
+	var buffer bytes.Buffer
+	if err := t.Execute(&buffer, c); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buffer.Bytes(), 0644)
+}
+
+func (c *Config) String() string {
+	var buffer bytes.Buffer
+	if err := t.Execute(&buffer, c); err != nil {
+		panic(err)
+	}
+
+	return buffer.String()
+}
+
+var (
+	configKeys = utils.ConfigKeys(Config{})
+)
+
+func ReadInConfigFile(v *viper.Viper) (*Config, error) {
+	config := NewConfig().WithDefaultValues()
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	if err := v.Unmarshal(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// ReadInConfig overrides the values of the configuration file with the
+// environment variables SENTINELNODE_OPENVPN_<KEY>, or the <KEY>_FILE variants.
+func ReadInConfig(v *viper.Viper) (*Config, error) {
+	config, err := ReadInConfigFile(v)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.SetFromEnv(v, EnvPrefix, configKeys); err != nil {
+		return nil, err
+	}
+	if err = v.Unmarshal(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func EnvOverrides() ([]utils.EnvOverride, error) {
+	return utils.EnvOverrides(EnvPrefix, configKeys)
+}
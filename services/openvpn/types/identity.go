@@ -0,0 +1,20 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// IdentityFromKey returns the username under which the client holding the
+// key authenticates to the server.
+func IdentityFromKey(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:16])
+}
+
+// PasswordFromKey returns the password the client holding the key submits
+// along with its username.
+func PasswordFromKey(key []byte) string {
+	return base64.RawURLEncoding.EncodeToString(key)
+}
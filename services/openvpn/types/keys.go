@@ -0,0 +1,19 @@
+package types
+
+const (
+	Type           = 3
+	ConfigFileName = "openvpn.toml"
+	KeyLen         = 32
+	EnvPrefix      = "SENTINELNODE_OPENVPN"
+)
+
+const (
+	TransportTCP = "tcp"
+	TransportUDP = "udp"
+)
+
+const (
+	CACertFileName     = "openvpn_ca.crt"
+	ServerCertFileName = "openvpn_server.crt"
+	ServerKeyFileName  = "openvpn_server.key"
+)
@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "proxy",
+		Short: "Proxy sub-commands",
+	}
+
+	cmd.AddCommand(
+		configCmd(),
+	)
+
+	return cmd
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	proxytypes "github.com/sentinel-official/dvpn-node/services/proxy/types"
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+func configCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Configuration sub-commands",
+	}
+
+	cmd.AddCommand(
+		configInit(),
+		configShow(),
+		configSet(),
+	)
+
+	return cmd
+}
+
+func configInit() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Init the configuration",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				home = viper.GetString(flags.FlagHome)
+				path = filepath.Join(home, proxytypes.ConfigFileName)
+			)
+
+			force, err := cmd.Flags().GetBool(types.FlagForce)
+			if err != nil {
+				return err
+			}
+
+			if !force {
+				if _, err = os.Stat(path); err == nil {
+					return fmt.Errorf("config file already exists at path %s", path)
+				}
+			}
+
+			if err = os.MkdirAll(home, 0700); err != nil {
+				return err
+			}
+
+			config := proxytypes.NewConfig().WithDefaultValues()
+			return config.SaveToPath(path)
+		},
+	}
+
+	cmd.Flags().Bool(types.FlagForce, false, "force")
+
+	return cmd
+}
+
+func configShow() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the configuration",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				home = viper.GetString(flags.FlagHome)
+				path = filepath.Join(home, proxytypes.ConfigFileName)
+			)
+
+			v := viper.New()
+			v.SetConfigFile(path)
+
+			config, err := proxytypes.ReadInConfig(v)
+			if err != nil {
+				return err
+			}
+
+			overrides, err := proxytypes.EnvOverrides()
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(config.String())
+			for _, item := range overrides {
+				fmt.Printf("# %s is set by the environment variable %s\n", item.Key, item.Env)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func configSet() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set [key] [value]",
+		Short: "Set the configuration",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			var (
+				home = viper.GetString(flags.FlagHome)
+				path = filepath.Join(home, proxytypes.ConfigFileName)
+			)
+
+			v := viper.New()
+			v.SetConfigFile(path)
+
+			config, err := proxytypes.ReadInConfigFile(v)
+			if err != nil {
+				return err
+			}
+
+			v.Set(args[0], args[1])
+
+			if err = v.Unmarshal(config); err != nil {
+				return err
+			}
+
+			return config.SaveToPath(path)
+		},
+	}
+
+	return cmd
+}
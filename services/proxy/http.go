@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+func httpReply(w io.Writer, status int, headers ...string) error {
+	reply := fmt.Sprintf("HTTP/1.1 %d %s\r\n", status, http.StatusText(status))
+	for _, header := range headers {
+		reply += header + "\r\n"
+	}
+
+	_, err := io.WriteString(w, reply+"\r\n")
+	return err
+}
+
+// httpHandshake reads a CONNECT request carrying the Basic credentials in the
+// Proxy-Authorization header, returning the identity of the authenticated user
+// and the destination address.
+func (s *Proxy) httpHandshake(r *bufio.Reader, w io.Writer) (identity, address string, err error) {
+	req, err := http.ReadRequest(r)
+	if err != nil {
+		return "", "", err
+	}
+
+	_ = req.Body.Close()
+
+	username, password, ok := "", "", false
+	if v, found := strings.CutPrefix(req.Header.Get("Proxy-Authorization"), "Basic "); found {
+		buf, err := base64.StdEncoding.DecodeString(v)
+		if err == nil {
+			username, password, ok = strings.Cut(string(buf), ":")
+		}
+	}
+
+	if !ok || !s.authenticate(username, password) {
+		_ = httpReply(w, http.StatusProxyAuthRequired, `Proxy-Authenticate: Basic realm="proxy"`)
+		return "", "", errors.New("authentication failed")
+	}
+	if req.Method != http.MethodConnect {
+		_ = httpReply(w, http.StatusMethodNotAllowed)
+		return "", "", fmt.Errorf("unsupported method %s", req.Method)
+	}
+
+	return username, req.Host, nil
+}
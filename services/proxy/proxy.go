@@ -0,0 +1,493 @@
+package proxy
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+
+	proxytypes "github.com/sentinel-official/dvpn-node/services/proxy/types"
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+const (
+	InfoLen = 2
+
+	dialTimeout      = 10 * time.Second
+	handshakeTimeout = 30 * time.Second
+)
+
+var (
+	_ types.Service = (*Proxy)(nil)
+
+	errNotAllowed = errors.New("destination address is not allowed")
+)
+
+// user holds the traffic and the open connections of an authenticated user,
+// along with the time its last connection was closed.
+type user struct {
+	upload   atomic.Int64
+	download atomic.Int64
+	conns    map[net.Conn]struct{}
+	closedAt time.Time
+}
+
+// counter counts the bytes written through it.
+type counter struct {
+	io.Writer
+	n *atomic.Int64
+}
+
+func (c *counter) Write(p []byte) (int, error) {
+	n, err := c.Writer.Write(p)
+	c.n.Add(int64(n))
+
+	return n, err
+}
+
+type Proxy struct {
+	sync.RWMutex
+	info     []byte
+	config   *proxytypes.Config
+	peers    *proxytypes.Peers
+	users    map[string]*user
+	added    map[string]time.Time
+	listener net.Listener
+	dialer   *net.Dialer
+	log      tmlog.Logger
+}
+
+func NewProxy(log tmlog.Logger) types.Service {
+	return &Proxy{
+		log:    log.With("service", "proxy"),
+		info:   make([]byte, InfoLen),
+		config: proxytypes.NewConfig(),
+		peers:  proxytypes.NewPeers(),
+		users:  make(map[string]*user),
+		added:  make(map[string]time.Time),
+		dialer: &net.Dialer{
+			Timeout: dialTimeout,
+			Control: control,
+		},
+	}
+}
+
+// control refuses the connections to the addresses which are not publicly
+// routable, so the peers cannot reach the services local to the node.
+func control(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return errors.Wrap(errNotAllowed, host)
+	}
+
+	return nil
+}
+
+func (s *Proxy) Type() uint64 {
+	return proxytypes.Type
+}
+
+func (s *Proxy) Init(home string) (err error) {
+	v := viper.New()
+	v.SetConfigFile(filepath.Join(home, proxytypes.ConfigFileName))
+
+	s.config, err = proxytypes.ReadInConfig(v)
+	if err != nil {
+		return err
+	}
+	if err = s.config.Validate(); err != nil {
+		return err
+	}
+
+	binary.BigEndian.PutUint16(s.info[:2], s.config.ListenPort)
+	return nil
+}
+
+func (s *Proxy) Info() []byte {
+	return s.info
+}
+
+func (s *Proxy) Start() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.config.ListenPort))
+	if err != nil {
+		return err
+	}
+
+	s.Lock()
+	s.listener = listener
+	s.Unlock()
+
+	go s.serve(listener)
+	return nil
+}
+
+func (s *Proxy) Stop() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.listener == nil {
+		return errors.New("listener is nil")
+	}
+
+	err := s.listener.Close()
+	s.listener = nil
+
+	for _, u := range s.users {
+		for conn := range u.conns {
+			_ = conn.Close()
+		}
+	}
+
+	return err
+}
+
+func (s *Proxy) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			s.log.Error("Failed to accept the proxy connection", "error", err)
+			continue
+		}
+
+		go s.handle(conn)
+	}
+}
+
+func (s *Proxy) authenticate(username, password string) bool {
+	peer := s.peers.Get(username)
+	if peer.Empty() {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(peer.Password), []byte(password)) == 1
+}
+
+// track registers the connections under the identity, failing when the peer
+// has been removed in the meantime.
+func (s *Proxy) track(identity string, conns ...net.Conn) (*user, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.peers.Get(identity).Empty() {
+		return nil, fmt.Errorf("peer %s does not exist", identity)
+	}
+
+	u, ok := s.users[identity]
+	if !ok {
+		u = &user{
+			conns: make(map[net.Conn]struct{}),
+		}
+		s.users[identity] = u
+	}
+
+	for _, conn := range conns {
+		u.conns[conn] = struct{}{}
+	}
+
+	return u, nil
+}
+
+func (s *Proxy) untrack(u *user, conns ...net.Conn) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, conn := range conns {
+		delete(u.conns, conn)
+	}
+
+	u.closedAt = time.Now()
+}
+
+// handle serves a client connection, telling SOCKS5 from HTTP by the version
+// byte SOCKS5 clients start with.
+func (s *Proxy) handle(conn net.Conn) {
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return
+	}
+
+	r := bufio.NewReader(conn)
+
+	b, err := r.Peek(1)
+	if err != nil {
+		return
+	}
+
+	var (
+		socks5            = b[0] == socks5Version
+		identity, address string
+	)
+
+	if socks5 {
+		identity, address, err = s.socks5Handshake(r, conn)
+	} else {
+		identity, address, err = s.httpHandshake(r, conn)
+	}
+	if err != nil {
+		return
+	}
+
+	target, err := s.dialer.Dial("tcp", address)
+	if err != nil {
+		if socks5 {
+			code := byte(socks5ReplyHostUnreachable)
+			if errors.Is(err, errNotAllowed) {
+				code = socks5ReplyNotAllowed
+			} else if errors.Is(err, syscall.ECONNREFUSED) {
+				code = socks5ReplyConnRefused
+			}
+
+			_ = socks5Reply(conn, code)
+		} else {
+			_ = httpReply(conn, http.StatusBadGateway)
+		}
+
+		return
+	}
+
+	defer target.Close()
+
+	u, err := s.track(identity, conn, target)
+	if err != nil {
+		if socks5 {
+			_ = socks5Reply(conn, socks5ReplyGeneralFailure)
+		}
+
+		return
+	}
+
+	defer s.untrack(u, conn, target)
+
+	if socks5 {
+		err = socks5Reply(conn, socks5ReplySucceeded)
+	} else {
+		err = httpReply(conn, http.StatusOK)
+	}
+	if err != nil {
+		return
+	}
+
+	if err = conn.SetDeadline(time.Time{}); err != nil {
+		return
+	}
+
+	// The bytes already buffered by the reader belong to the tunnel.
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(&counter{Writer: target, n: &u.upload}, r)
+		_ = target.Close()
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(&counter{Writer: conn, n: &u.download}, target)
+		_ = conn.Close()
+		done <- struct{}{}
+	}()
+
+	<-done
+	<-done
+}
+
+func (s *Proxy) AddPeer(data []byte) (result []byte, err error) {
+	if len(data) != proxytypes.KeyLen {
+		return nil, fmt.Errorf("data length must be %d bytes", proxytypes.KeyLen)
+	}
+
+	identity := proxytypes.IdentityFromKey(data)
+	s.peers.Put(
+		proxytypes.Peer{
+			Identity: identity,
+			Key:      base64.StdEncoding.EncodeToString(data),
+			Password: proxytypes.PasswordFromKey(data),
+		},
+	)
+
+	s.Lock()
+	s.added[identity] = time.Now()
+	s.Unlock()
+
+	return nil, nil
+}
+
+func (s *Proxy) HasPeer(data []byte) bool {
+	var (
+		identity = proxytypes.IdentityFromKey(data)
+		peer     = s.peers.Get(identity)
+	)
+
+	return !peer.Empty()
+}
+
+func (s *Proxy) RemovePeer(data []byte) error {
+	if len(data) != proxytypes.KeyLen {
+		return fmt.Errorf("data length must be %d bytes", proxytypes.KeyLen)
+	}
+
+	identity := proxytypes.IdentityFromKey(data)
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.peers.Delete(identity)
+	delete(s.added, identity)
+	if u, ok := s.users[identity]; ok {
+		for conn := range u.conns {
+			_ = conn.Close()
+		}
+
+		delete(s.users, identity)
+	}
+
+	return nil
+}
+
+func (s *Proxy) RekeyPeer(data, newData []byte) ([]byte, error) {
+	if !s.HasPeer(data) {
+		return nil, fmt.Errorf("peer %s does not exist", base64.StdEncoding.EncodeToString(data))
+	}
+	if s.HasPeer(newData) {
+		return nil, fmt.Errorf("peer %s already exists", base64.StdEncoding.EncodeToString(newData))
+	}
+	if len(newData) != proxytypes.KeyLen {
+		return nil, fmt.Errorf("data length must be %d bytes", proxytypes.KeyLen)
+	}
+
+	if err := s.RemovePeer(data); err != nil {
+		return nil, err
+	}
+
+	result, err := s.AddPeer(newData)
+	if err != nil {
+		_, _ = s.AddPeer(data)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Proxy) Peers() (items []types.Peer, err error) {
+	s.RLock()
+	defer s.RUnlock()
+
+	now := time.Now()
+	err = s.peers.Iterate(
+		func(key string, value proxytypes.Peer) (bool, error) {
+			item := types.Peer{
+				Key:          value.Key,
+				LastActivity: s.added[key],
+			}
+
+			if u, ok := s.users[key]; ok {
+				item.Upload = u.upload.Load()
+				item.Download = u.download.Load()
+
+				if len(u.conns) > 0 {
+					item.LastActivity = now
+				} else if u.closedAt.After(item.LastActivity) {
+					item.LastActivity = u.closedAt
+				}
+			}
+
+			items = append(items, item)
+			return false, nil
+		},
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (s *Proxy) PeerCount() int {
+	return s.peers.Len()
+}
+
+func (s *Proxy) Health() types.ServiceHealth {
+	s.RLock()
+	defer s.RUnlock()
+
+	return types.ServiceHealth{
+		Running:  s.listener != nil,
+		Restarts: 0,
+	}
+}
+
+func (s *Proxy) Ports() []uint16 {
+	return []uint16{s.config.ListenPort}
+}
+
+func (s *Proxy) Endpoints() []types.ServiceEndpoint {
+	return []types.ServiceEndpoint{
+		{
+			Protocol:  "socks5",
+			Port:      s.config.ListenPort,
+			Transport: "tcp",
+			TLS:       false,
+		},
+		{
+			Protocol:  "http",
+			Port:      s.config.ListenPort,
+			Transport: "tcp",
+			TLS:       false,
+		},
+	}
+}
+
+func (s *Proxy) PeerConfig(host string, data []byte) (*types.PeerConfig, error) {
+	if len(data) != proxytypes.KeyLen {
+		return nil, fmt.Errorf("data length must be %d bytes", proxytypes.KeyLen)
+	}
+
+	peer := s.peers.Get(proxytypes.IdentityFromKey(data))
+	if peer.Empty() {
+		return nil, fmt.Errorf("peer %s does not exist", base64.StdEncoding.EncodeToString(data))
+	}
+
+	u := &url.URL{
+		Scheme: "socks5",
+		User:   url.UserPassword(peer.Identity, peer.Password),
+		Host:   net.JoinHostPort(host, strconv.Itoa(int(s.config.ListenPort))),
+	}
+
+	config := &types.PeerConfig{
+		Type:      s.Type(),
+		Host:      host,
+		Port:      s.config.ListenPort,
+		Protocol:  "socks5",
+		Transport: "tcp",
+		TLS:       false,
+		Config:    u.String(),
+	}
+
+	u.Scheme = "http"
+	config.Config += "\n" + u.String()
+
+	return config, nil
+}
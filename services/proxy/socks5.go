@@ -0,0 +1,150 @@
+package proxy
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// SOCKS5 (RFC 1928) with the username/password authentication (RFC 1929);
+// only the CONNECT command is supported.
+const (
+	socks5Version        = 0x05
+	socks5AuthVersion    = 0x01
+	socks5MethodUserPass = 0x02
+	socks5MethodNone     = 0xFF
+	socks5CmdConnect     = 0x01
+	socks5AddrIPv4       = 0x01
+	socks5AddrDomain     = 0x03
+	socks5AddrIPv6       = 0x04
+)
+
+const (
+	socks5ReplySucceeded        = 0x00
+	socks5ReplyGeneralFailure   = 0x01
+	socks5ReplyNotAllowed       = 0x02
+	socks5ReplyHostUnreachable  = 0x04
+	socks5ReplyConnRefused      = 0x05
+	socks5ReplyCmdNotSupported  = 0x07
+	socks5ReplyAddrNotSupported = 0x08
+)
+
+func socks5Reply(w io.Writer, code byte) error {
+	_, err := w.Write([]byte{socks5Version, code, 0x00, socks5AddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+func readString(r io.Reader) (string, error) {
+	var n [1]byte
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, n[0])
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
+
+// socks5Handshake negotiates the authentication and reads the CONNECT request,
+// returning the identity of the authenticated user and the destination address.
+func (s *Proxy) socks5Handshake(r *bufio.Reader, w io.Writer) (identity, address string, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return "", "", err
+	}
+
+	methods := make([]byte, header[1])
+	if _, err = io.ReadFull(r, methods); err != nil {
+		return "", "", err
+	}
+
+	supported := false
+	for _, method := range methods {
+		if method == socks5MethodUserPass {
+			supported = true
+			break
+		}
+	}
+
+	if !supported {
+		_, _ = w.Write([]byte{socks5Version, socks5MethodNone})
+		return "", "", errors.New("username/password authentication is not offered")
+	}
+	if _, err = w.Write([]byte{socks5Version, socks5MethodUserPass}); err != nil {
+		return "", "", err
+	}
+
+	var version [1]byte
+	if _, err = io.ReadFull(r, version[:]); err != nil {
+		return "", "", err
+	}
+	if version[0] != socks5AuthVersion {
+		return "", "", fmt.Errorf("invalid authentication version %d", version[0])
+	}
+
+	username, err := readString(r)
+	if err != nil {
+		return "", "", err
+	}
+
+	password, err := readString(r)
+	if err != nil {
+		return "", "", err
+	}
+
+	if !s.authenticate(username, password) {
+		_, _ = w.Write([]byte{socks5AuthVersion, 0x01})
+		return "", "", errors.New("authentication failed")
+	}
+	if _, err = w.Write([]byte{socks5AuthVersion, 0x00}); err != nil {
+		return "", "", err
+	}
+
+	var request [4]byte
+	if _, err = io.ReadFull(r, request[:]); err != nil {
+		return "", "", err
+	}
+	if request[1] != socks5CmdConnect {
+		_ = socks5Reply(w, socks5ReplyCmdNotSupported)
+		return "", "", fmt.Errorf("unsupported command %d", request[1])
+	}
+
+	var host string
+	switch request[3] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		size := net.IPv4len
+		if request[3] == socks5AddrIPv6 {
+			size = net.IPv6len
+		}
+
+		buf := make([]byte, size)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return "", "", err
+		}
+
+		host = net.IP(buf).String()
+	case socks5AddrDomain:
+		if host, err = readString(r); err != nil {
+			return "", "", err
+		}
+	default:
+		_ = socks5Reply(w, socks5ReplyAddrNotSupported)
+		return "", "", fmt.Errorf("unsupported address type %d", request[3])
+	}
+
+	var port [2]byte
+	if _, err = io.ReadFull(r, port[:]); err != nil {
+		return "", "", err
+	}
+
+	address = net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port[:]))))
+	return username, address, nil
+}
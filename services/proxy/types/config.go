@@ -0,0 +1,106 @@
+package types
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+
+	"github.com/sentinel-official/dvpn-node/utils"
+)
+
+var (
+	ct = strings.TrimSpace(`
+# Port number to accept the incoming SOCKS5 and HTTP CONNECT connections
+listen_port = {{ .ListenPort }}
+	`)
+
+	t = func() *template.Template {
+		t, err := template.New("proxy_toml").Parse(ct)
+		if err != nil {
+			panic(err)
+		}
+
+		return t
+	}()
+)
+
+type Config struct {
+	ListenPort uint16 `json:"listen_port" mapstructure:"listen_port"`
+}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func (c *Config) Validate() error {
+	if c.ListenPort == 0 {
+		return errors.New("listen_port cannot be zero")
+	}
+
+	return nil
+}
+
+func (c *Config) WithDefaultValues() *Config {
+	c.ListenPort = utils.RandomPort()
+
+	return c
+}
+
+func (c *Config) SaveToPath(path string) error {
+	var buffer bytes.Buffer
+	if err := t.Execute(&buffer, c); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buffer.Bytes(), 0644)
+}
+
+func (c *Config) String() string {
+	var buffer bytes.Buffer
+	if err := t.Execute(&buffer, c); err != nil {
+		panic(err)
+	}
+
+	return buffer.String()
+}
+
+var (
+	configKeys = utils.ConfigKeys(Config{})
+)
+
+func ReadInConfigFile(v *viper.Viper) (*Config, error) {
+	config := NewConfig().WithDefaultValues()
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	if err := v.Unmarshal(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// ReadInConfig overrides the values of the configuration file with the
+// environment variables SENTINELNODE_PROXY_<KEY>, or the <KEY>_FILE variants.
+func ReadInConfig(v *viper.Viper) (*Config, error) {
+	config, err := ReadInConfigFile(v)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.SetFromEnv(v, EnvPrefix, configKeys); err != nil {
+		return nil, err
+	}
+	if err = v.Unmarshal(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func EnvOverrides() ([]utils.EnvOverride, error) {
+	return utils.EnvOverrides(EnvPrefix, configKeys)
+}
@@ -0,0 +1,18 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// IdentityFromKey returns the username of the credentials derived from the key.
+func IdentityFromKey(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:16])
+}
+
+// PasswordFromKey returns the password of the credentials derived from the key.
+func PasswordFromKey(key []byte) string {
+	return base64.RawURLEncoding.EncodeToString(key)
+}
@@ -0,0 +1,8 @@
+package types
+
+const (
+	Type           = 4
+	ConfigFileName = "proxy.toml"
+	KeyLen         = 32
+	EnvPrefix      = "SENTINELNODE_PROXY"
+)
@@ -0,0 +1,19 @@
+package v2ray
+
+import (
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+type backend interface {
+	Start() error
+	Stop() error
+	AddInbound(config *core.InboundHandlerConfig) error
+	RemoveInbound(tag string) error
+	AddUser(tag string, user *protocol.User) error
+	RemoveUser(tag, email string) error
+	Traffic(emails []string) ([]types.Peer, error)
+	Health() types.ServiceHealth
+}
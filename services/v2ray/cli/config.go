@@ -81,7 +81,16 @@ func configShow() *cobra.Command {
 				return err
 			}
 
+			overrides, err := v2raytypes.EnvOverrides()
+			if err != nil {
+				return err
+			}
+
 			fmt.Println(config.String())
+			for _, item := range overrides {
+				fmt.Printf("# %s is set by the environment variable %s\n", item.Key, item.Env)
+			}
+
 			return nil
 		},
 	}
@@ -103,7 +112,7 @@ func configSet() *cobra.Command {
 			v := viper.New()
 			v.SetConfigFile(path)
 
-			config, err := v2raytypes.ReadInConfig(v)
+			config, err := v2raytypes.ReadInConfigFile(v)
 			if err != nil {
 				return err
 			}
@@ -1,12 +1,72 @@
 package v2ray
 
 import (
+	"encoding/json"
 	"strings"
+	"text/template"
 )
 
 var (
+	configFuncs = template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			buf, err := json.Marshal(v)
+			return string(buf), err
+		},
+	}
+
+	streamTemplate = `{{ define "stream" }}{
+{{- if eq .Transport "grpc" "gun" }}
+                "grpcSettings": {
+                    "serviceName": {{ json .ServiceName }}
+                },
+{{- else if eq .Transport "http" }}
+                "httpSettings": {
+                    "host": [{{ with .Host }}{{ json . }}{{ end }}],
+                    "path": {{ json .Path }}
+                },
+{{- else if eq .Transport "mkcp" }}
+                "kcpSettings": {
+                    "header": {
+                        "type": {{ json .HeaderType }}
+                    }
+                },
+{{- else if eq .Transport "quic" }}
+                "quicSettings": {
+                    "header": {
+                        "type": {{ json .HeaderType }}
+                    },
+                    "security": "chacha20-poly1305"
+                },
+{{- else if eq .Transport "websocket" }}
+                "wsSettings": {
+                    "headers": {
+{{- with .Host }}
+                        "Host": {{ json . }}
+{{- end }}
+                    },
+                    "path": {{ json .Path }}
+                },
+{{- end }}
+                "network": "{{ .Transport }}",
+                "security": "{{ .Security }}",
+                "tlsSettings": {
+                    "allowInsecure": true,
+{{- with .ALPN }}
+                    "alpn": {{ json . }},
+{{- end }}
+                    "certificates": [
+                        {
+                            "certificateFile": "{{ .TLSCertPath }}",
+                            "keyFile": "{{ .TLSKeyPath }}"
+                        }
+                    ],
+                    "serverName": {{ json .ServerName }}
+                }
+            }{{ end }}`
+
 	configTemplate = strings.TrimSpace(`
 {
+{{- if not .Embedded }}
     "api": {
         "services": [
             "HandlerService",
@@ -14,7 +74,9 @@ var (
         ],
         "tag": "api"
     },
+{{- end }}
     "inbounds": [
+{{- if not .Embedded }}
         {
             "listen": "127.0.0.1",
             "port": 23,
@@ -24,24 +86,62 @@ var (
             },
             "tag": "api"
         },
+{{- end }}
         {
             "port": "{{ .VMess.ListenPort }}",
             "protocol": "vmess",
+            "streamSettings": {{ template "stream" .VMess.StreamConfig }},
+            "tag": "vmess"
+        }{{ range $i, $v := .VMess.Inbounds }},
+        {
+            "port": "{{ $v.ListenPort }}",
+            "protocol": "vmess",
+            "streamSettings": {{ template "stream" $v }},
+            "tag": "{{ $.VMess.InboundTag $i }}"
+        }{{ end }}{{ if .VLess.Enable }},
+        {
+            "port": "{{ .VLess.ListenPort }}",
+            "protocol": "vless",
+            "settings": {
+                "clients": [],
+                "decryption": "none"
+            },
             "streamSettings": {
-                "network": "{{ .VMess.Transport }}",
-                "security": "{{ .VMess.Security }}",
+                "network": "{{ .VLess.Transport }}",
+                "security": "{{ .VLess.Security }}",
                 "tlsSettings": {
                     "allowInsecure": true,
                     "certificates": [
                         {
-                            "certificateFile": "{{ .VMess.TLSCertPath }}",
-                            "keyFile": "{{ .VMess.TLSKeyPath }}"
+                            "certificateFile": "{{ .VLess.TLSCertPath }}",
+                            "keyFile": "{{ .VLess.TLSKeyPath }}"
                         }
                     ]
                 }
             },
-            "tag": "vmess"
-        }
+            "tag": "vless"
+        }{{ end }}{{ if .Trojan.Enable }},
+        {
+            "port": "{{ .Trojan.ListenPort }}",
+            "protocol": "trojan",
+            "settings": {
+                "clients": []
+            },
+            "streamSettings": {
+                "network": "{{ .Trojan.Transport }}",
+                "security": "{{ .Trojan.Security }}",
+                "tlsSettings": {
+                    "allowInsecure": true,
+                    "certificates": [
+                        {
+                            "certificateFile": "{{ .Trojan.TLSCertPath }}",
+                            "keyFile": "{{ .Trojan.TLSKeyPath }}"
+                        }
+                    ]
+                }
+            },
+            "tag": "trojan"
+        }{{ end }}
     ],
     "log": {
         "access": "none",
@@ -61,6 +161,7 @@ var (
             }
         }
     },
+{{- if not .Embedded }}
     "routing": {
         "rules": [
             {
@@ -72,6 +173,7 @@ var (
             }
         ]
     },
+{{- end }}
     "stats": {},
     "transport": {
         "dsSettings": {},
@@ -0,0 +1,148 @@
+package v2ray
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/features/inbound"
+	"github.com/v2fly/v2ray-core/v5/features/stats"
+	"github.com/v2fly/v2ray-core/v5/proxy"
+
+	"github.com/sentinel-official/dvpn-node/types"
+
+	_ "github.com/v2fly/v2ray-core/v5/main/distro/all"
+)
+
+var (
+	_ backend = (*embedded)(nil)
+)
+
+type embedded struct {
+	config   []byte
+	instance *core.Instance
+}
+
+func newEmbedded(config []byte) *embedded {
+	return &embedded{
+		config: config,
+	}
+}
+
+func (b *embedded) Start() error {
+	config, err := core.LoadConfig(core.FormatJSON, bytes.NewReader(b.config))
+	if err != nil {
+		return err
+	}
+
+	b.instance, err = core.New(config)
+	if err != nil {
+		return err
+	}
+
+	return b.instance.Start()
+}
+
+func (b *embedded) Stop() error {
+	if b.instance == nil {
+		return errors.New("instance is nil")
+	}
+
+	return b.instance.Close()
+}
+
+func (b *embedded) Health() types.ServiceHealth {
+	return types.ServiceHealth{
+		Running:  b.instance != nil,
+		Restarts: 0,
+	}
+}
+
+func (b *embedded) inboundManager() inbound.Manager {
+	return b.instance.GetFeature(inbound.ManagerType()).(inbound.Manager)
+}
+
+func (b *embedded) statsManager() stats.Manager {
+	return b.instance.GetFeature(stats.ManagerType()).(stats.Manager)
+}
+
+func (b *embedded) userManager(tag string) (proxy.UserManager, error) {
+	handler, err := b.inboundManager().GetHandler(context.TODO(), tag)
+	if err != nil {
+		return nil, fmt.Errorf("inbound %s not found", tag)
+	}
+
+	gi, ok := handler.(proxy.GetInbound)
+	if !ok {
+		return nil, fmt.Errorf("inbound %s does not have a proxy", tag)
+	}
+
+	um, ok := gi.GetInbound().(proxy.UserManager)
+	if !ok {
+		return nil, fmt.Errorf("inbound %s does not manage users", tag)
+	}
+
+	return um, nil
+}
+
+func (b *embedded) AddInbound(config *core.InboundHandlerConfig) error {
+	return core.AddInboundHandler(b.instance, config)
+}
+
+func (b *embedded) RemoveInbound(tag string) error {
+	if _, err := b.inboundManager().GetHandler(context.TODO(), tag); err != nil {
+		return fmt.Errorf("inbound %s not found", tag)
+	}
+
+	return b.inboundManager().RemoveHandler(context.TODO(), tag)
+}
+
+func (b *embedded) AddUser(tag string, user *protocol.User) error {
+	um, err := b.userManager(tag)
+	if err != nil {
+		return err
+	}
+
+	mu, err := user.ToMemoryUser()
+	if err != nil {
+		return err
+	}
+
+	return um.AddUser(context.TODO(), mu)
+}
+
+func (b *embedded) RemoveUser(tag, email string) error {
+	um, err := b.userManager(tag)
+	if err != nil {
+		return err
+	}
+
+	return um.RemoveUser(context.TODO(), email)
+}
+
+func (b *embedded) stat(name string) int64 {
+	counter := b.statsManager().GetCounter(name)
+	if counter == nil {
+		return 0
+	}
+
+	return counter.Value()
+}
+
+func (b *embedded) Traffic(emails []string) (items []types.Peer, err error) {
+	for _, email := range emails {
+		items = append(
+			items,
+			types.Peer{
+				Key:      email,
+				Upload:   b.stat(fmt.Sprintf("user>>>%s>>>traffic>>>uplink", email)),
+				Download: b.stat(fmt.Sprintf("user>>>%s>>>traffic>>>downlink", email)),
+			},
+		)
+	}
+
+	return items, nil
+}
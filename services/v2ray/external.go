@@ -0,0 +1,401 @@
+package v2ray
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+	core "github.com/v2fly/v2ray-core/v5"
+	proxymancommand "github.com/v2fly/v2ray-core/v5/app/proxyman/command"
+	statscommand "github.com/v2fly/v2ray-core/v5/app/stats/command"
+	"github.com/v2fly/v2ray-core/v5/common/protocol"
+	"github.com/v2fly/v2ray-core/v5/common/serial"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+const (
+	apiTarget         = "127.0.0.1:23"
+	callTimeout       = 10 * time.Second
+	minRestartBackoff = 1 * time.Second
+	maxRestartBackoff = 1 * time.Minute
+)
+
+var (
+	_ backend = (*external)(nil)
+)
+
+type external struct {
+	sync.RWMutex
+	cmd        *exec.Cmd
+	configPath string
+	onRestart  func() error
+	log        tmlog.Logger
+
+	conn          *grpc.ClientConn
+	handlerClient proxymancommand.HandlerServiceClient
+	statsClient   statscommand.StatsServiceClient
+
+	alive    bool
+	ready    bool
+	stopped  bool
+	restarts uint64
+
+	base map[string]types.Peer
+	last map[string]types.Peer
+}
+
+func newExternal(configPath string, onRestart func() error, log tmlog.Logger) *external {
+	return &external{
+		configPath: configPath,
+		onRestart:  onRestart,
+		log:        log,
+		base:       make(map[string]types.Peer),
+		last:       make(map[string]types.Peer),
+	}
+}
+
+func (b *external) start() error {
+	cmd := exec.Command("v2ray", strings.Split(
+		fmt.Sprintf("run --config %s", b.configPath), " ")...)
+
+	cmd.Env = os.Environ()
+	cmd.Env = append(cmd.Env, "V2RAY_VMESS_AEAD_FORCED=false")
+
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	if b.stopped {
+		_ = cmd.Process.Kill()
+		return errors.New("v2ray process has been stopped")
+	}
+
+	b.cmd = cmd
+	b.alive = true
+
+	return nil
+}
+
+func (b *external) Start() error {
+	conn, err := grpc.Dial(
+		apiTarget,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.WaitForReady(true)),
+	)
+	if err != nil {
+		return err
+	}
+
+	b.conn = conn
+	b.handlerClient = proxymancommand.NewHandlerServiceClient(conn)
+	b.statsClient = statscommand.NewStatsServiceClient(conn)
+
+	if err = b.start(); err != nil {
+		return err
+	}
+
+	b.Lock()
+	b.ready = true
+	b.Unlock()
+
+	go b.supervise()
+	return nil
+}
+
+func (b *external) Stop() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.cmd == nil {
+		return errors.New("command is nil")
+	}
+
+	b.stopped = true
+	if err := b.cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+
+	return b.conn.Close()
+}
+
+func (b *external) isStopped() bool {
+	b.RLock()
+	defer b.RUnlock()
+
+	return b.stopped
+}
+
+func (b *external) isAlive() bool {
+	b.RLock()
+	defer b.RUnlock()
+
+	return b.alive
+}
+
+func (b *external) supervise() {
+	backoff := minRestartBackoff
+	for {
+		b.RLock()
+		cmd := b.cmd
+		b.RUnlock()
+
+		startedAt := time.Now()
+		err := cmd.Wait()
+
+		b.Lock()
+		b.alive, b.ready = false, false
+		for key, v := range b.last {
+			b.base[key] = v
+		}
+		b.Unlock()
+
+		if b.isStopped() {
+			return
+		}
+
+		b.log.Error("The V2Ray process exited", "error", err)
+		if time.Since(startedAt) > maxRestartBackoff {
+			backoff = minRestartBackoff
+		}
+
+		for {
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > maxRestartBackoff {
+				backoff = maxRestartBackoff
+			}
+
+			if b.isStopped() {
+				return
+			}
+
+			b.Lock()
+			b.restarts++
+			b.Unlock()
+
+			b.log.Info("Restarting the V2Ray process", "backoff", backoff)
+			if err = b.start(); err != nil {
+				b.log.Error("Failed to restart the V2Ray process", "error", err)
+				continue
+			}
+
+			break
+		}
+
+		b.conn.ResetConnectBackoff()
+		if b.onRestart != nil {
+			if err = b.onRestart(); err != nil {
+				b.log.Error("Failed to restore the V2Ray users", "error", err)
+				b.RLock()
+				_ = b.cmd.Process.Kill()
+				b.RUnlock()
+				continue
+			}
+		}
+
+		b.Lock()
+		b.ready = true
+		b.Unlock()
+	}
+}
+
+func (b *external) Health() types.ServiceHealth {
+	b.RLock()
+	defer b.RUnlock()
+
+	return types.ServiceHealth{
+		Running:  b.ready,
+		Restarts: b.restarts,
+	}
+}
+
+func (b *external) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), callTimeout)
+}
+
+func (b *external) AddInbound(config *core.InboundHandlerConfig) error {
+	if !b.isAlive() {
+		return errors.New("v2ray process is not running")
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+
+	req := &proxymancommand.AddInboundRequest{
+		Inbound: config,
+	}
+
+	_, err := b.handlerClient.AddInbound(ctx, req)
+	return err
+}
+
+func (b *external) RemoveInbound(tag string) error {
+	if !b.isAlive() {
+		return nil
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+
+	req := &proxymancommand.RemoveInboundRequest{
+		Tag: tag,
+	}
+
+	_, err := b.handlerClient.RemoveInbound(ctx, req)
+	return err
+}
+
+func (b *external) AddUser(tag string, user *protocol.User) error {
+	if !b.isAlive() {
+		return errors.New("v2ray process is not running")
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+
+	req := &proxymancommand.AlterInboundRequest{
+		Tag: tag,
+		Operation: serial.ToTypedMessage(
+			&proxymancommand.AddUserOperation{
+				User: user,
+			},
+		),
+	}
+
+	_, err := b.handlerClient.AlterInbound(ctx, req)
+	return err
+}
+
+func (b *external) RemoveUser(tag, email string) error {
+	if !b.isAlive() {
+		return nil
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+
+	req := &proxymancommand.AlterInboundRequest{
+		Tag: tag,
+		Operation: serial.ToTypedMessage(
+			&proxymancommand.RemoveUserOperation{
+				Email: email,
+			},
+		),
+	}
+
+	_, err := b.handlerClient.AlterInbound(ctx, req)
+	return err
+}
+
+// cached returns the last known traffic of the peers, used while the process is down.
+func (b *external) cached(emails []string) (items []types.Peer) {
+	b.RLock()
+	defer b.RUnlock()
+
+	for _, email := range emails {
+		v := b.base[email]
+		items = append(
+			items,
+			types.Peer{
+				Key:      email,
+				Upload:   v.Upload,
+				Download: v.Download,
+			},
+		)
+	}
+
+	return items
+}
+
+// userStats returns the traffic of all the users in a single call, keyed by email.
+// The counters are named as user>>>{email}>>>traffic>>>{uplink|downlink}.
+func (b *external) userStats() (map[string]types.Peer, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	req := &statscommand.QueryStatsRequest{
+		Patterns: []string{"user>>>"},
+		Reset_:   false,
+	}
+
+	res, err := b.statsClient.QueryStats(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(map[string]types.Peer)
+	for _, stat := range res.GetStat() {
+		names := strings.Split(stat.GetName(), ">>>")
+		if len(names) != 4 || names[0] != "user" || names[2] != "traffic" {
+			continue
+		}
+
+		item := items[names[1]]
+		switch names[3] {
+		case "uplink":
+			item.Upload = stat.GetValue()
+		case "downlink":
+			item.Download = stat.GetValue()
+		default:
+			continue
+		}
+
+		items[names[1]] = item
+	}
+
+	return items, nil
+}
+
+func (b *external) Traffic(emails []string) (items []types.Peer, err error) {
+	if !b.isAlive() {
+		return b.cached(emails), nil
+	}
+
+	stats, err := b.userStats()
+	if err != nil {
+		return nil, err
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	last := make(map[string]types.Peer)
+	for _, email := range emails {
+		var (
+			v    = stats[email]
+			base = b.base[email]
+		)
+
+		item := types.Peer{
+			Key:      email,
+			Upload:   base.Upload + v.Upload,
+			Download: base.Download + v.Download,
+		}
+
+		items = append(items, item)
+		last[email] = item
+	}
+
+	for key := range b.base {
+		if _, ok := last[key]; !ok {
+			delete(b.base, key)
+		}
+	}
+
+	b.last = last
+	return items, nil
+}
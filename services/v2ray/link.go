@@ -0,0 +1,142 @@
+package v2ray
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	v2raytypes "github.com/sentinel-official/dvpn-node/services/v2ray/types"
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+type vmessLink struct {
+	V    string `json:"v"`
+	PS   string `json:"ps"`
+	Add  string `json:"add"`
+	Port string `json:"port"`
+	ID   string `json:"id"`
+	Aid  string `json:"aid"`
+	Scy  string `json:"scy"`
+	Net  string `json:"net"`
+	Type string `json:"type"`
+	Host string `json:"host"`
+	Path string `json:"path"`
+	TLS  string `json:"tls"`
+	SNI  string `json:"sni,omitempty"`
+	ALPN string `json:"alpn,omitempty"`
+}
+
+func setTransportQuery(query url.Values, endpoint *types.ServiceEndpoint) {
+	transport := v2raytypes.NewTransportFromString(endpoint.Transport)
+	query.Set("type", transport.Network())
+
+	if endpoint.Host != "" {
+		query.Set("host", endpoint.Host)
+	}
+	if endpoint.Path != "" {
+		query.Set("path", endpoint.Path)
+	}
+	if endpoint.ServiceName != "" {
+		query.Set("serviceName", endpoint.ServiceName)
+	}
+	if endpoint.HeaderType != "" && endpoint.HeaderType != "none" {
+		query.Set("headerType", endpoint.HeaderType)
+	}
+	if endpoint.ServerName != "" {
+		query.Set("sni", endpoint.ServerName)
+	}
+	if len(endpoint.ALPN) > 0 {
+		query.Set("alpn", strings.Join(endpoint.ALPN, ","))
+	}
+}
+
+// The certificate of the node is self-signed, so the links with TLS have the
+// clients skip its verification.
+func (s *V2Ray) shareLink(proxy v2raytypes.Proxy, endpoint *types.ServiceEndpoint, host, id string) (string, error) {
+	var (
+		port      = endpoint.Port
+		transport = v2raytypes.NewTransportFromString(endpoint.Transport)
+	)
+
+	switch proxy.String() {
+	case "vmess":
+		link := vmessLink{
+			V:    "2",
+			PS:   host,
+			Add:  host,
+			Port: fmt.Sprintf("%d", port),
+			ID:   id,
+			Aid:  "0",
+			Scy:  "auto",
+			Net:  transport.Network(),
+			Type: "none",
+			Host: endpoint.Host,
+			Path: endpoint.Path,
+			SNI:  endpoint.ServerName,
+			ALPN: strings.Join(endpoint.ALPN, ","),
+		}
+		if endpoint.HeaderType != "" {
+			link.Type = endpoint.HeaderType
+		}
+		if endpoint.ServiceName != "" {
+			link.Path = endpoint.ServiceName
+		}
+		if endpoint.TLS {
+			link.TLS = "tls"
+		}
+
+		buf, err := json.Marshal(link)
+		if err != nil {
+			return "", err
+		}
+
+		return "vmess://" + base64.StdEncoding.EncodeToString(buf), nil
+	case "vless":
+		query := url.Values{}
+		query.Set("encryption", "none")
+		setTransportQuery(query, endpoint)
+		if endpoint.TLS {
+			query.Set("security", "tls")
+			query.Set("allowInsecure", "1")
+		} else {
+			query.Set("security", "none")
+		}
+
+		link := url.URL{
+			Scheme:   "vless",
+			User:     url.User(id),
+			Host:     net.JoinHostPort(host, fmt.Sprintf("%d", port)),
+			RawQuery: query.Encode(),
+			Fragment: host,
+		}
+
+		return link.String(), nil
+	case "trojan":
+		query := url.Values{}
+		query.Set("security", "tls")
+		query.Set("allowInsecure", "1")
+		setTransportQuery(query, endpoint)
+
+		link := url.URL{
+			Scheme:   "trojan",
+			User:     url.User(id),
+			Host:     net.JoinHostPort(host, fmt.Sprintf("%d", port)),
+			RawQuery: query.Encode(),
+			Fragment: host,
+		}
+
+		return link.String(), nil
+	case "shadowsocks":
+		var (
+			userinfo = fmt.Sprintf("%s:%s", s.config.Shadowsocks.Cipher, id)
+			address  = net.JoinHostPort(host, fmt.Sprintf("%d", port))
+		)
+
+		return fmt.Sprintf("ss://%s@%s#%s", base64.RawURLEncoding.EncodeToString([]byte(userinfo)), address, url.PathEscape(host)), nil
+	default:
+		return "", fmt.Errorf("invalid proxy %d", proxy)
+	}
+}
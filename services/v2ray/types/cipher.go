@@ -0,0 +1,18 @@
+package types
+
+import (
+	"github.com/v2fly/v2ray-core/v5/proxy/shadowsocks"
+)
+
+func NewCipherTypeFromString(v string) shadowsocks.CipherType {
+	switch v {
+	case "aes-128-gcm":
+		return shadowsocks.CipherType_AES_128_GCM
+	case "aes-256-gcm":
+		return shadowsocks.CipherType_AES_256_GCM
+	case "chacha20-poly1305":
+		return shadowsocks.CipherType_CHACHA20_POLY1305
+	default:
+		return shadowsocks.CipherType_UNKNOWN
+	}
+}
@@ -9,21 +9,97 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+	"github.com/v2fly/v2ray-core/v5/proxy/shadowsocks"
 
 	"github.com/sentinel-official/dvpn-node/utils"
 )
 
 var (
 	ct = strings.TrimSpace(`
+# Mode of running the V2Ray instance; external (v2ray binary) or embedded (in-process)
+mode = "{{ .Mode }}"
+
+[shadowsocks]
+# Enable or disable the Shadowsocks inbounds
+enable = {{ .Shadowsocks.Enable }}
+
+# Name of the AEAD cipher; aes-128-gcm, aes-256-gcm or chacha20-poly1305
+cipher = "{{ .Shadowsocks.Cipher }}"
+
+# Lowest port number of the range used for per-peer inbounds
+min_port = {{ .Shadowsocks.MinPort }}
+
+# Highest port number of the range used for per-peer inbounds
+max_port = {{ .Shadowsocks.MaxPort }}
+
+[trojan]
+# Enable or disable the Trojan inbound
+enable = {{ .Trojan.Enable }}
+
+# Port number to accept the incoming connections
+listen_port = {{ .Trojan.ListenPort }}
+
+# Enable or disable TLS for secure connections; must be enabled for Trojan
+tls = {{ .Trojan.TLS }}
+
+# Name of the transport protocol
+transport = "{{ .Trojan.Transport }}"
+
+[vless]
+# Enable or disable the VLESS inbound
+enable = {{ .VLess.Enable }}
+
+# Port number to accept the incoming connections
+listen_port = {{ .VLess.ListenPort }}
+
+# Enable or disable TLS for secure connections
+tls = {{ .VLess.TLS }}
+
+# Name of the transport protocol
+transport = "{{ .VLess.Transport }}"
+
 [vmess]
+# Application protocols advertised over TLS, e.g. ["h2", "http/1.1"]
+alpn = [{{ range $i, $v := .VMess.ALPN }}{{ if $i }}, {{ end }}"{{ $v }}"{{ end }}]
+
+# Type of the packet header for the mkcp and quic transports; none, srtp, utp, wechat-video, dtls or wireguard
+header_type = "{{ .VMess.HeaderType }}"
+
+# Value of the Host header for the websocket and http transports
+host = "{{ .VMess.Host }}"
+
 # Port number to accept the incoming connections
 listen_port = {{ .VMess.ListenPort }}
 
+# Request path for the websocket and http transports
+path = "{{ .VMess.Path }}"
+
+# Server name (SNI) of the TLS certificate
+server_name = "{{ .VMess.ServerName }}"
+
+# Service name for the grpc and gun transports
+service_name = "{{ .VMess.ServiceName }}"
+
 # Enable or disable TLS for secure connections
 tls = {{ .VMess.TLS }}
 
 # Name of the transport protocol
 transport = "{{ .VMess.Transport }}"
+
+# Additional inbounds sharing the same users are added as [[vmess.inbounds]] tables with the keys above
+{{- range .VMess.Inbounds }}
+
+[[vmess.inbounds]]
+alpn = [{{ range $i, $v := .ALPN }}{{ if $i }}, {{ end }}"{{ $v }}"{{ end }}]
+header_type = "{{ .HeaderType }}"
+host = "{{ .Host }}"
+listen_port = {{ .ListenPort }}
+path = "{{ .Path }}"
+server_name = "{{ .ServerName }}"
+service_name = "{{ .ServiceName }}"
+tls = {{ .TLS }}
+transport = "{{ .Transport }}"
+{{- end }}
 	`)
 
 	t = func() *template.Template {
@@ -36,29 +112,269 @@ transport = "{{ .VMess.Transport }}"
 	}()
 )
 
+type StreamConfig struct {
+	Security    string `json:"security"`
+	TLSCertPath string `json:"tls_cert_path"`
+	TLSKeyPath  string `json:"tls_key_path"`
+
+	ALPN        []string `json:"alpn" mapstructure:"alpn"`
+	HeaderType  string   `json:"header_type" mapstructure:"header_type"`
+	Host        string   `json:"host" mapstructure:"host"`
+	ListenPort  uint16   `json:"listen_port" mapstructure:"listen_port"`
+	Path        string   `json:"path" mapstructure:"path"`
+	ServerName  string   `json:"server_name" mapstructure:"server_name"`
+	ServiceName string   `json:"service_name" mapstructure:"service_name"`
+	TLS         bool     `json:"tls" mapstructure:"tls"`
+	Transport   string   `json:"transport" mapstructure:"transport"`
+}
+
+func NewStreamConfig() *StreamConfig {
+	return &StreamConfig{}
+}
+
+func (c *StreamConfig) WithDefaultValues() *StreamConfig {
+	c.ALPN = []string{}
+	c.HeaderType = "none"
+	c.Host = ""
+	c.ListenPort = utils.RandomPort()
+	c.Path = ""
+	c.ServerName = ""
+	c.ServiceName = ""
+	c.TLS = false
+	c.Transport = "grpc"
+
+	return c
+}
+
+func (c *StreamConfig) Validate() error {
+	if c.ListenPort == 0 {
+		return errors.New("listen_port cannot be zero")
+	}
+	if c.Transport == "" {
+		return errors.New("transport cannot be empty")
+	}
+
+	t := NewTransportFromString(c.Transport)
+	if !t.IsValid() {
+		return fmt.Errorf("invalid transport %s", c.Transport)
+	}
+
+	switch t.String() {
+	case "websocket", "http":
+		if c.Path != "" && !strings.HasPrefix(c.Path, "/") {
+			return errors.New("path must start with /")
+		}
+	default:
+		if c.Path != "" {
+			return fmt.Errorf("path is not supported by transport %s", c.Transport)
+		}
+		if c.Host != "" {
+			return fmt.Errorf("host is not supported by transport %s", c.Transport)
+		}
+	}
+
+	switch t.String() {
+	case "grpc", "gun":
+	default:
+		if c.ServiceName != "" {
+			return fmt.Errorf("service_name is not supported by transport %s", c.Transport)
+		}
+	}
+
+	switch c.HeaderType {
+	case "", "none":
+	case "srtp", "utp", "wechat-video", "dtls", "wireguard":
+		if t.String() != "mkcp" && t.String() != "quic" {
+			return fmt.Errorf("header_type is not supported by transport %s", c.Transport)
+		}
+	default:
+		return fmt.Errorf("invalid header_type %s", c.HeaderType)
+	}
+
+	if !c.TLS {
+		if c.ServerName != "" {
+			return errors.New("server_name requires tls to be enabled")
+		}
+		if len(c.ALPN) > 0 {
+			return errors.New("alpn requires tls to be enabled")
+		}
+	}
+	for _, v := range c.ALPN {
+		if v == "" {
+			return errors.New("alpn cannot contain an empty value")
+		}
+	}
+
+	return nil
+}
+
 type VMessConfig struct {
+	StreamConfig `mapstructure:",squash"`
+
+	Inbounds []*StreamConfig `json:"inbounds" mapstructure:"inbounds"`
+}
+
+func NewVMessConfig() *VMessConfig {
+	return &VMessConfig{}
+}
+
+func (c *VMessConfig) WithDefaultValues() *VMessConfig {
+	c.StreamConfig.WithDefaultValues()
+	c.Inbounds = []*StreamConfig{}
+
+	return c
+}
+
+func (c *VMessConfig) Validate() error {
+	if err := c.StreamConfig.Validate(); err != nil {
+		return err
+	}
+
+	for i, v := range c.Inbounds {
+		if err := v.Validate(); err != nil {
+			return errors.Wrapf(err, "invalid inbound %d", i+1)
+		}
+	}
+
+	return nil
+}
+
+func (c *VMessConfig) InboundTag(i int) string {
+	return fmt.Sprintf("vmess-%d", i+1)
+}
+
+func (c *VMessConfig) Tags() []string {
+	tags := []string{"vmess"}
+	for i := range c.Inbounds {
+		tags = append(tags, c.InboundTag(i))
+	}
+
+	return tags
+}
+
+type ShadowsocksConfig struct {
+	Enable  bool   `json:"enable" mapstructure:"enable"`
+	Cipher  string `json:"cipher" mapstructure:"cipher"`
+	MinPort uint16 `json:"min_port" mapstructure:"min_port"`
+	MaxPort uint16 `json:"max_port" mapstructure:"max_port"`
+}
+
+func NewShadowsocksConfig() *ShadowsocksConfig {
+	return &ShadowsocksConfig{}
+}
+
+func (c *ShadowsocksConfig) WithDefaultValues() *ShadowsocksConfig {
+	port := utils.RandomPort()
+	if port > 1<<16-1-MaxShadowsocksPorts {
+		port -= MaxShadowsocksPorts
+	}
+
+	c.Enable = false
+	c.Cipher = "chacha20-poly1305"
+	c.MinPort = port
+	c.MaxPort = port + MaxShadowsocksPorts - 1
+
+	return c
+}
+
+func (c *ShadowsocksConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.Cipher == "" {
+		return errors.New("cipher cannot be empty")
+	}
+	if NewCipherTypeFromString(c.Cipher) == shadowsocks.CipherType_UNKNOWN {
+		return fmt.Errorf("invalid cipher %s", c.Cipher)
+	}
+	if c.MinPort == 0 {
+		return errors.New("min_port cannot be zero")
+	}
+	if c.MaxPort < c.MinPort {
+		return errors.New("max_port cannot be less than min_port")
+	}
+
+	return nil
+}
+
+func (c *ShadowsocksConfig) Contains(port uint16) bool {
+	return c.Enable && port >= c.MinPort && port <= c.MaxPort
+}
+
+type TrojanConfig struct {
 	Security    string `json:"security"`
 	TLSCertPath string `json:"tls_cert_path"`
 	TLSKeyPath  string `json:"tls_key_path"`
 
+	Enable     bool   `json:"enable" mapstructure:"enable"`
 	ListenPort uint16 `json:"listen_port" mapstructure:"listen_port"`
 	TLS        bool   `json:"tls" mapstructure:"tls"`
 	Transport  string `json:"transport" mapstructure:"transport"`
 }
 
-func NewVMessConfig() *VMessConfig {
-	return &VMessConfig{}
+func NewTrojanConfig() *TrojanConfig {
+	return &TrojanConfig{}
 }
 
-func (c *VMessConfig) WithDefaultValues() *VMessConfig {
+func (c *TrojanConfig) WithDefaultValues() *TrojanConfig {
+	c.Enable = false
 	c.ListenPort = utils.RandomPort()
-	c.TLS = false
+	c.TLS = true
+	c.Transport = "tcp"
+
+	return c
+}
+
+func (c *TrojanConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
+	if c.ListenPort == 0 {
+		return errors.New("listen_port cannot be zero")
+	}
+	if !c.TLS {
+		return errors.New("tls must be enabled")
+	}
+	if c.Transport == "" {
+		return errors.New("transport cannot be empty")
+	}
+
+	t := NewTransportFromString(c.Transport)
+	if !t.IsValid() {
+		return fmt.Errorf("invalid transport %s", c.Transport)
+	}
+
+	return nil
+}
+
+type VLessConfig struct {
+	Security    string `json:"security"`
+	TLSCertPath string `json:"tls_cert_path"`
+	TLSKeyPath  string `json:"tls_key_path"`
+
+	Enable     bool   `json:"enable" mapstructure:"enable"`
+	ListenPort uint16 `json:"listen_port" mapstructure:"listen_port"`
+	TLS        bool   `json:"tls" mapstructure:"tls"`
+	Transport  string `json:"transport" mapstructure:"transport"`
+}
+
+func NewVLessConfig() *VLessConfig {
+	return &VLessConfig{}
+}
+
+func (c *VLessConfig) WithDefaultValues() *VLessConfig {
+	c.Enable = false
+	c.ListenPort = utils.RandomPort()
+	c.TLS = true
 	c.Transport = "grpc"
 
 	return c
 }
 
-func (c *VMessConfig) Validate() error {
+func (c *VLessConfig) Validate() error {
+	if !c.Enable {
+		return nil
+	}
 	if c.ListenPort == 0 {
 		return errors.New("listen_port cannot be zero")
 	}
@@ -75,29 +391,82 @@ func (c *VMessConfig) Validate() error {
 }
 
 type Config struct {
-	VMess *VMessConfig `json:"vmess" mapstructure:"vmess"`
+	Mode        string             `json:"mode" mapstructure:"mode"`
+	Shadowsocks *ShadowsocksConfig `json:"shadowsocks" mapstructure:"shadowsocks"`
+	Trojan      *TrojanConfig      `json:"trojan" mapstructure:"trojan"`
+	VLess       *VLessConfig       `json:"vless" mapstructure:"vless"`
+	VMess       *VMessConfig       `json:"vmess" mapstructure:"vmess"`
 }
 
 func NewConfig() *Config {
 	return &Config{
-		VMess: NewVMessConfig(),
+		Shadowsocks: NewShadowsocksConfig(),
+		Trojan:      NewTrojanConfig(),
+		VLess:       NewVLessConfig(),
+		VMess:       NewVMessConfig(),
 	}
 }
 
 func (c *Config) Validate() error {
+	if c.Mode != ModeExternal && c.Mode != ModeEmbedded {
+		return fmt.Errorf("invalid mode %s", c.Mode)
+	}
+	if err := c.Shadowsocks.Validate(); err != nil {
+		return errors.Wrapf(err, "invalid section shadowsocks")
+	}
+	if err := c.Trojan.Validate(); err != nil {
+		return errors.Wrapf(err, "invalid section trojan")
+	}
+	if err := c.VLess.Validate(); err != nil {
+		return errors.Wrapf(err, "invalid section vless")
+	}
 	if err := c.VMess.Validate(); err != nil {
 		return errors.Wrapf(err, "invalid section vmess")
 	}
 
+	ports := map[uint16]string{c.VMess.ListenPort: "vmess"}
+	for i, v := range c.VMess.Inbounds {
+		tag := c.VMess.InboundTag(i)
+		if u, ok := ports[v.ListenPort]; ok {
+			return fmt.Errorf("%s listen_port cannot be same as %s listen_port", tag, u)
+		}
+		ports[v.ListenPort] = tag
+	}
+	if c.VLess.Enable {
+		if v, ok := ports[c.VLess.ListenPort]; ok {
+			return fmt.Errorf("vless listen_port cannot be same as %s listen_port", v)
+		}
+		ports[c.VLess.ListenPort] = "vless"
+	}
+	if c.Trojan.Enable {
+		if v, ok := ports[c.Trojan.ListenPort]; ok {
+			return fmt.Errorf("trojan listen_port cannot be same as %s listen_port", v)
+		}
+		ports[c.Trojan.ListenPort] = "trojan"
+	}
+	for port, v := range ports {
+		if c.Shadowsocks.Contains(port) {
+			return fmt.Errorf("shadowsocks port range cannot contain %s listen_port", v)
+		}
+	}
+
 	return nil
 }
 
 func (c *Config) WithDefaultValues() *Config {
+	c.Mode = ModeExternal
+	c.Shadowsocks = c.Shadowsocks.WithDefaultValues()
+	c.Trojan = c.Trojan.WithDefaultValues()
+	c.VLess = c.VLess.WithDefaultValues()
 	c.VMess = c.VMess.WithDefaultValues()
 
 	return c
 }
 
+func (c *Config) Embedded() bool {
+	return c.Mode == ModeEmbedded
+}
+
 func (c *Config) SaveToPath(path string) error {
 	var buf bytes.Buffer
 	if err := t.Execute(&buf, c); err != nil {
@@ -116,7 +485,11 @@ func (c *Config) String() string {
 	return buf.String()
 }
 
-func ReadInConfig(v *viper.Viper) (*Config, error) {
+var (
+	configKeys = utils.ConfigKeys(Config{})
+)
+
+func ReadInConfigFile(v *viper.Viper) (*Config, error) {
 	config := NewConfig().WithDefaultValues()
 	if err := v.ReadInConfig(); err != nil {
 		return nil, err
@@ -127,3 +500,24 @@ func ReadInConfig(v *viper.Viper) (*Config, error) {
 
 	return config, nil
 }
+
+// ReadInConfig overrides the values of the configuration file with the
+// environment variables SENTINELNODE_V2RAY_<KEY>, or the <KEY>_FILE variants.
+func ReadInConfig(v *viper.Viper) (*Config, error) {
+	config, err := ReadInConfigFile(v)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.SetFromEnv(v, EnvPrefix, configKeys); err != nil {
+		return nil, err
+	}
+	if err = v.Unmarshal(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func EnvOverrides() ([]utils.EnvOverride, error) {
+	return utils.EnvOverrides(EnvPrefix, configKeys)
+}
@@ -1,6 +1,13 @@
 package types
 
 const (
-	Type           = 2
-	ConfigFileName = "v2ray.toml"
+	Type                = 2
+	ConfigFileName      = "v2ray.toml"
+	EnvPrefix           = "SENTINELNODE_V2RAY"
+	MaxShadowsocksPorts = 256
+)
+
+const (
+	ModeExternal = "external"
+	ModeEmbedded = "embedded"
 )
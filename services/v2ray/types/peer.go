@@ -6,6 +6,7 @@ import (
 
 type Peer struct {
 	Email string
+	Port  uint16
 }
 
 func (p Peer) Empty() bool {
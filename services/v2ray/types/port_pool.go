@@ -0,0 +1,54 @@
+package types
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+type PortPool struct {
+	min       uint16
+	max       uint16
+	current   uint32
+	available []uint16
+	reserved  map[uint16]bool
+	mutex     *sync.Mutex
+}
+
+func NewPortPool(min, max uint16) *PortPool {
+	return &PortPool{
+		min:      min,
+		max:      max,
+		current:  uint32(min),
+		reserved: make(map[uint16]bool),
+		mutex:    &sync.Mutex{},
+	}
+}
+
+func (p *PortPool) Get() (port uint16, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.available) == 0 {
+		if p.current > uint32(p.max) {
+			return 0, errors.New("port pool is full")
+		}
+
+		port, p.current = uint16(p.current), p.current+1
+	} else {
+		port, p.available = p.available[0], p.available[1:]
+	}
+
+	p.reserved[port] = true
+	return port, nil
+}
+
+func (p *PortPool) Release(port uint16) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.reserved[port] {
+		delete(p.reserved, port)
+		p.available = append(p.available, port)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
 	"github.com/v2fly/v2ray-core/v5/common/uuid"
+	"github.com/v2fly/v2ray-core/v5/proxy/shadowsocks"
+	"github.com/v2fly/v2ray-core/v5/proxy/trojan"
+	"github.com/v2fly/v2ray-core/v5/proxy/vless"
 	"github.com/v2fly/v2ray-core/v5/proxy/vmess"
 	"google.golang.org/protobuf/types/known/anypb"
 )
@@ -22,10 +25,24 @@ func (p Proxy) Tag() string {
 	return p.String()
 }
 
+func (p Proxy) PeerTag(email string) string {
+	if p.String() == "shadowsocks" {
+		return p.String() + "-" + email
+	}
+
+	return p.Tag()
+}
+
 func (p Proxy) String() string {
 	switch p.Byte() {
 	case 0x01:
 		return "vmess"
+	case 0x02:
+		return "vless"
+	case 0x03:
+		return "trojan"
+	case 0x04:
+		return "shadowsocks"
 	default:
 		return ""
 	}
@@ -44,7 +61,30 @@ func (p Proxy) Account(uid uuid.UUID) *anypb.Any {
 				TestsEnabled: "",
 			},
 		)
+	case 0x02:
+		return serial.ToTypedMessage(
+			&vless.Account{
+				Id:         uid.String(),
+				Flow:       "",
+				Encryption: "none",
+			},
+		)
+	case 0x03:
+		return serial.ToTypedMessage(
+			&trojan.Account{
+				Password: uid.String(),
+			},
+		)
 	default:
 		return nil
 	}
 }
+
+func NewShadowsocksAccount(uid uuid.UUID, cipher string) *anypb.Any {
+	return serial.ToTypedMessage(
+		&shadowsocks.Account{
+			Password:   uid.String(),
+			CipherType: NewCipherTypeFromString(cipher),
+		},
+	)
+}
@@ -55,3 +55,24 @@ func NewTransportFromString(v string) Transport {
 		return 0x00
 	}
 }
+
+func (t Transport) Network() string {
+	switch t.Byte() {
+	case 0x01:
+		return "tcp"
+	case 0x02:
+		return "kcp"
+	case 0x03:
+		return "ws"
+	case 0x04:
+		return "h2"
+	case 0x05:
+		return "ds"
+	case 0x06:
+		return "quic"
+	case 0x07, 0x08:
+		return "grpc"
+	default:
+		return ""
+	}
+}
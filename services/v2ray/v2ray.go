@@ -2,25 +2,26 @@ package v2ray
 
 import (
 	"bytes"
-	"context"
 	"encoding/base64"
 	"encoding/binary"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
-	proxymancommand "github.com/v2fly/v2ray-core/v5/app/proxyman/command"
-	statscommand "github.com/v2fly/v2ray-core/v5/app/stats/command"
+	tmlog "github.com/tendermint/tendermint/libs/log"
+	core "github.com/v2fly/v2ray-core/v5"
+	"github.com/v2fly/v2ray-core/v5/app/proxyman"
+	v2raynet "github.com/v2fly/v2ray-core/v5/common/net"
 	"github.com/v2fly/v2ray-core/v5/common/protocol"
 	"github.com/v2fly/v2ray-core/v5/common/serial"
 	"github.com/v2fly/v2ray-core/v5/common/uuid"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"github.com/v2fly/v2ray-core/v5/proxy/shadowsocks"
 
 	v2raytypes "github.com/sentinel-official/dvpn-node/services/v2ray/types"
 	"github.com/sentinel-official/dvpn-node/types"
@@ -28,26 +29,39 @@ import (
 )
 
 const (
-	InfoLen = 2 + 1 + 1
+	InfoLen        = 2 + 1 + 1
+	InboundInfoLen = 1 + 2 + 1 + 1
 )
 
 var (
 	_ types.Service = (*V2Ray)(nil)
 )
 
+// activity is the traffic of a peer as last observed and the time it last changed.
+type activity struct {
+	traffic int64
+	at      time.Time
+}
+
 type V2Ray struct {
-	info   []byte
-	cmd    *exec.Cmd
-	config *v2raytypes.Config
-	peers  *v2raytypes.Peers
+	info     []byte
+	backend  backend
+	config   *v2raytypes.Config
+	peers    *v2raytypes.Peers
+	ports    *v2raytypes.PortPool
+	mu       sync.Mutex
+	activity map[string]activity
+	log      tmlog.Logger
 }
 
-func NewV2Ray() *V2Ray {
+func NewV2Ray(log tmlog.Logger) *V2Ray {
 	return &V2Ray{
-		info:   make([]byte, InfoLen),
-		cmd:    nil,
-		config: v2raytypes.NewConfig(),
-		peers:  v2raytypes.NewPeers(),
+		info:     make([]byte, InfoLen),
+		backend:  nil,
+		config:   v2raytypes.NewConfig(),
+		peers:    v2raytypes.NewPeers(),
+		activity: make(map[string]activity),
+		log:      log.With("service", "v2ray"),
 	}
 }
 
@@ -81,17 +95,47 @@ func (s *V2Ray) Init(home string) (err error) {
 	s.config.VMess.TLSCertPath = filepath.Join(home, "tls.crt")
 	s.config.VMess.TLSKeyPath = filepath.Join(home, "tls.key")
 
-	t, err := template.New("v2ray_json").Parse(configTemplate)
+	for _, inbound := range s.config.VMess.Inbounds {
+		if inbound.TLS {
+			inbound.Security = "tls"
+		}
+		inbound.TLSCertPath = filepath.Join(home, "tls.crt")
+		inbound.TLSKeyPath = filepath.Join(home, "tls.key")
+	}
+
+	if s.config.VLess.TLS {
+		s.config.VLess.Security = "tls"
+	}
+	s.config.VLess.TLSCertPath = filepath.Join(home, "tls.crt")
+	s.config.VLess.TLSKeyPath = filepath.Join(home, "tls.key")
+
+	if s.config.Trojan.TLS {
+		s.config.Trojan.Security = "tls"
+	}
+	s.config.Trojan.TLSCertPath = filepath.Join(home, "tls.crt")
+	s.config.Trojan.TLSKeyPath = filepath.Join(home, "tls.key")
+
+	t, err := template.New("v2ray_json").Funcs(configFuncs).Parse(configTemplate)
 	if err != nil {
 		return err
 	}
+	if _, err = t.Parse(streamTemplate); err != nil {
+		return err
+	}
 
 	var buf bytes.Buffer
 	if err = t.Execute(&buf, s.config); err != nil {
 		return err
 	}
-	if err = os.WriteFile(s.configFilePath(), buf.Bytes(), 0600); err != nil {
-		return err
+
+	if s.config.Embedded() {
+		s.backend = newEmbedded(buf.Bytes())
+	} else {
+		if err = os.WriteFile(s.configFilePath(), buf.Bytes(), 0600); err != nil {
+			return err
+		}
+
+		s.backend = newExternal(s.configFilePath(), s.restore, s.log)
 	}
 
 	binary.BigEndian.PutUint16(s.info[0:], s.config.VMess.ListenPort)
@@ -99,57 +143,186 @@ func (s *V2Ray) Init(home string) (err error) {
 	s.info[2] = transport.Byte()
 	s.info[3] = utils.ByteFromBool(s.config.VMess.TLS)
 
+	for _, inbound := range s.config.VMess.Inbounds {
+		info := make([]byte, InboundInfoLen)
+		info[0] = v2raytypes.Proxy(0x01).Byte()
+		binary.BigEndian.PutUint16(info[1:], inbound.ListenPort)
+		info[3] = v2raytypes.NewTransportFromString(inbound.Transport).Byte()
+		info[4] = utils.ByteFromBool(inbound.TLS)
+
+		s.info = append(s.info, info...)
+	}
+
+	if s.config.VLess.Enable {
+		info := make([]byte, InboundInfoLen)
+		info[0] = v2raytypes.Proxy(0x02).Byte()
+		binary.BigEndian.PutUint16(info[1:], s.config.VLess.ListenPort)
+		info[3] = v2raytypes.NewTransportFromString(s.config.VLess.Transport).Byte()
+		info[4] = utils.ByteFromBool(s.config.VLess.TLS)
+
+		s.info = append(s.info, info...)
+	}
+	if s.config.Trojan.Enable {
+		info := make([]byte, InboundInfoLen)
+		info[0] = v2raytypes.Proxy(0x03).Byte()
+		binary.BigEndian.PutUint16(info[1:], s.config.Trojan.ListenPort)
+		info[3] = v2raytypes.NewTransportFromString(s.config.Trojan.Transport).Byte()
+		info[4] = utils.ByteFromBool(s.config.Trojan.TLS)
+
+		s.info = append(s.info, info...)
+	}
+	if s.config.Shadowsocks.Enable {
+		info := make([]byte, InboundInfoLen)
+		info[0] = v2raytypes.Proxy(0x04).Byte()
+		binary.BigEndian.PutUint16(info[1:], s.config.Shadowsocks.MinPort)
+		info[3] = v2raytypes.NewTransportFromString("tcp").Byte()
+		info[4] = utils.ByteFromBool(false)
+
+		s.info = append(s.info, info...)
+		s.ports = v2raytypes.NewPortPool(s.config.Shadowsocks.MinPort, s.config.Shadowsocks.MaxPort)
+	}
+
 	return nil
 }
 
-func (s *V2Ray) Start() error {
-	s.cmd = exec.Command("v2ray", strings.Split(
-		fmt.Sprintf("run --config %s", s.configFilePath()), " ")...)
+func streamEndpoint(proxy v2raytypes.Proxy, c *v2raytypes.StreamConfig) *types.ServiceEndpoint {
+	return &types.ServiceEndpoint{
+		Protocol:    proxy.String(),
+		Port:        c.ListenPort,
+		Transport:   c.Transport,
+		TLS:         c.TLS,
+		ALPN:        c.ALPN,
+		HeaderType:  c.HeaderType,
+		Host:        c.Host,
+		Path:        c.Path,
+		ServerName:  c.ServerName,
+		ServiceName: c.ServiceName,
+	}
+}
 
-	s.cmd.Env = os.Environ()
-	s.cmd.Env = append(s.cmd.Env, "V2RAY_VMESS_AEAD_FORCED=false")
+func (s *V2Ray) endpoint(proxy v2raytypes.Proxy) (*types.ServiceEndpoint, error) {
+	switch proxy.String() {
+	case "vmess":
+		return streamEndpoint(proxy, &s.config.VMess.StreamConfig), nil
+	case "vless":
+		if !s.config.VLess.Enable {
+			return nil, errors.New("proxy vless is not enabled")
+		}
 
-	s.cmd.Stdout = os.Stdout
-	s.cmd.Stderr = os.Stderr
+		return &types.ServiceEndpoint{
+			Protocol:  proxy.String(),
+			Port:      s.config.VLess.ListenPort,
+			Transport: s.config.VLess.Transport,
+			TLS:       s.config.VLess.TLS,
+		}, nil
+	case "trojan":
+		if !s.config.Trojan.Enable {
+			return nil, errors.New("proxy trojan is not enabled")
+		}
 
-	return s.cmd.Start()
+		return &types.ServiceEndpoint{
+			Protocol:  proxy.String(),
+			Port:      s.config.Trojan.ListenPort,
+			Transport: s.config.Trojan.Transport,
+			TLS:       s.config.Trojan.TLS,
+		}, nil
+	case "shadowsocks":
+		if !s.config.Shadowsocks.Enable {
+			return nil, errors.New("proxy shadowsocks is not enabled")
+		}
+
+		return &types.ServiceEndpoint{
+			Protocol:  proxy.String(),
+			Port:      s.config.Shadowsocks.MinPort,
+			Transport: "tcp",
+			TLS:       false,
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid proxy %d", proxy)
+	}
 }
 
-func (s *V2Ray) Stop() error {
-	if s.cmd == nil {
-		return errors.New("command is nil")
+func (s *V2Ray) Endpoints() (items []types.ServiceEndpoint) {
+	for _, proxy := range []v2raytypes.Proxy{0x01, 0x02, 0x03, 0x04} {
+		endpoint, err := s.endpoint(proxy)
+		if err != nil {
+			continue
+		}
+
+		items = append(items, *endpoint)
+		if proxy.String() == "vmess" {
+			for _, inbound := range s.config.VMess.Inbounds {
+				items = append(items, *streamEndpoint(proxy, inbound))
+			}
+		}
 	}
 
-	return s.cmd.Process.Kill()
+	return items
 }
 
-func (s *V2Ray) clientConn() (*grpc.ClientConn, error) {
-	target := "127.0.0.1:23"
-	return grpc.Dial(
-		target,
-		grpc.WithBlock(),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+// Ports returns the ports of the endpoints along with the whole Shadowsocks range.
+func (s *V2Ray) Ports() (items []uint16) {
+	for _, endpoint := range s.Endpoints() {
+		items = append(items, endpoint.Port)
+	}
+
+	if s.config.Shadowsocks.Enable {
+		for port := uint32(s.config.Shadowsocks.MinPort) + 1; port <= uint32(s.config.Shadowsocks.MaxPort); port++ {
+			items = append(items, uint16(port))
+		}
+	}
+
+	return items
 }
 
-func (s *V2Ray) handlerServiceClient() (*grpc.ClientConn, proxymancommand.HandlerServiceClient, error) {
-	conn, err := s.clientConn()
-	if err != nil {
-		return nil, nil, err
+// tags returns the tags of all the inbounds sharing the users of the proxy.
+func (s *V2Ray) tags(proxy v2raytypes.Proxy) []string {
+	if proxy.String() == "vmess" {
+		return s.config.VMess.Tags()
 	}
 
-	client := proxymancommand.NewHandlerServiceClient(conn)
-	return conn, client, nil
+	return []string{proxy.Tag()}
 }
 
-func (s *V2Ray) statsServiceClient() (*grpc.ClientConn, statscommand.StatsServiceClient, error) {
-	conn, err := s.clientConn()
-	if err != nil {
-		return nil, nil, err
+func (s *V2Ray) addUser(proxy v2raytypes.Proxy, user *protocol.User) (err error) {
+	var added []string
+	defer func() {
+		if err != nil {
+			for _, tag := range added {
+				_ = s.backend.RemoveUser(tag, user.Email)
+			}
+		}
+	}()
+
+	for _, tag := range s.tags(proxy) {
+		if err = s.backend.AddUser(tag, user); err != nil {
+			return err
+		}
+
+		added = append(added, tag)
 	}
 
-	client := statscommand.NewStatsServiceClient(conn)
-	return conn, client, nil
+	return nil
+}
+
+func (s *V2Ray) removeUser(proxy v2raytypes.Proxy, email string) error {
+	for _, tag := range s.tags(proxy) {
+		if err := s.backend.RemoveUser(tag, email); err != nil {
+			if !strings.Contains(err.Error(), "not found") {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *V2Ray) Start() error {
+	return s.backend.Start()
+}
+
+func (s *V2Ray) Stop() error {
+	return s.backend.Stop()
 }
 
 func (s *V2Ray) AddPeer(data []byte) (result []byte, err error) {
@@ -157,48 +330,121 @@ func (s *V2Ray) AddPeer(data []byte) (result []byte, err error) {
 		return nil, errors.New("data length must be 17 bytes")
 	}
 
-	conn, client, err := s.handlerServiceClient()
-	if err != nil {
-		return nil, err
-	}
-
-	defer func() {
-		if err = conn.Close(); err != nil {
-			panic(err)
-		}
-	}()
-
 	var (
 		email  = base64.StdEncoding.EncodeToString(data)
 		proxy  = v2raytypes.Proxy(data[0])
 		uid, _ = uuid.ParseBytes(data[1:])
 	)
 
-	req := &proxymancommand.AlterInboundRequest{
-		Tag: proxy.Tag(),
-		Operation: serial.ToTypedMessage(
-			&proxymancommand.AddUserOperation{
+	if _, err = s.endpoint(proxy); err != nil {
+		return nil, err
+	}
+	if !s.backend.Health().Running {
+		return nil, errors.New("v2ray is not running")
+	}
+
+	if proxy.String() == "shadowsocks" {
+		return s.addShadowsocksPeer(email, uid)
+	}
+
+	user := &protocol.User{
+		Level:   0,
+		Email:   email,
+		Account: proxy.Account(uid),
+	}
+
+	if err = s.addUser(proxy, user); err != nil {
+		return nil, err
+	}
+
+	s.peers.Put(
+		v2raytypes.Peer{
+			Email: email,
+		},
+	)
+
+	return result, nil
+}
+
+func (s *V2Ray) shadowsocksInbound(email string, uid uuid.UUID, port uint16) *core.InboundHandlerConfig {
+	return &core.InboundHandlerConfig{
+		Tag: v2raytypes.Proxy(0x04).PeerTag(email),
+		ReceiverSettings: serial.ToTypedMessage(
+			&proxyman.ReceiverConfig{
+				PortRange: v2raynet.SinglePortRange(v2raynet.Port(port)),
+				Listen:    v2raynet.NewIPOrDomain(v2raynet.AnyIP),
+			},
+		),
+		ProxySettings: serial.ToTypedMessage(
+			&shadowsocks.ServerConfig{
 				User: &protocol.User{
 					Level:   0,
 					Email:   email,
-					Account: proxy.Account(uid),
+					Account: v2raytypes.NewShadowsocksAccount(uid, s.config.Shadowsocks.Cipher),
+				},
+				Network: []v2raynet.Network{
+					v2raynet.Network_TCP,
+					v2raynet.Network_UDP,
 				},
 			},
 		),
 	}
+}
 
-	_, err = client.AlterInbound(context.TODO(), req)
+func (s *V2Ray) addShadowsocksPeer(email string, uid uuid.UUID) (result []byte, err error) {
+	port, err := s.ports.Get()
 	if err != nil {
 		return nil, err
 	}
 
+	defer func() {
+		if err != nil {
+			s.ports.Release(port)
+		}
+	}()
+
+	if err = s.backend.AddInbound(s.shadowsocksInbound(email, uid, port)); err != nil {
+		return nil, err
+	}
+
 	s.peers.Put(
 		v2raytypes.Peer{
 			Email: email,
+			Port:  port,
 		},
 	)
 
-	return result, nil
+	// The port is left out of the result, which the unversioned API sends as
+	// it is, and is given in the configuration of the peer instead.
+	return nil, nil
+}
+
+func (s *V2Ray) restore() error {
+	return s.peers.Iterate(
+		func(key string, value v2raytypes.Peer) (bool, error) {
+			data, err := base64.StdEncoding.DecodeString(key)
+			if err != nil {
+				return false, err
+			}
+
+			var (
+				proxy  = v2raytypes.Proxy(data[0])
+				uid, _ = uuid.ParseBytes(data[1:])
+			)
+
+			if proxy.String() == "shadowsocks" {
+				return false, s.backend.AddInbound(s.shadowsocksInbound(key, uid, value.Port))
+			}
+
+			user := &protocol.User{
+				Level:   0,
+				Email:   key,
+				Account: proxy.Account(uid),
+			}
+
+			return false, s.addUser(proxy, user)
+		},
+	)
 }
 
 func (s *V2Ray) HasPeer(data []byte) bool {
@@ -215,110 +461,154 @@ func (s *V2Ray) RemovePeer(data []byte) error {
 		return errors.New("data length must be 17 bytes")
 	}
 
-	conn, client, err := s.handlerServiceClient()
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		if err = conn.Close(); err != nil {
-			panic(err)
-		}
-	}()
-
 	var (
 		email = base64.StdEncoding.EncodeToString(data)
 		proxy = v2raytypes.Proxy(data[0])
 	)
 
-	req := &proxymancommand.AlterInboundRequest{
-		Tag: proxy.Tag(),
-		Operation: serial.ToTypedMessage(
-			&proxymancommand.RemoveUserOperation{
-				Email: email,
-			},
-		),
+	if proxy.String() == "shadowsocks" {
+		return s.removeShadowsocksPeer(email)
 	}
 
-	_, err = client.AlterInbound(context.TODO(), req)
-	if err != nil {
+	if err := s.removeUser(proxy, email); err != nil {
+		return err
+	}
+
+	s.peers.Delete(email)
+
+	return nil
+}
+
+func (s *V2Ray) removeShadowsocksPeer(email string) error {
+	if err := s.backend.RemoveInbound(v2raytypes.Proxy(0x04).PeerTag(email)); err != nil {
 		if !strings.Contains(err.Error(), "not found") {
 			return err
 		}
 	}
 
-	s.peers.Delete(email)
+	if v := s.peers.Get(email); !v.Empty() {
+		s.peers.Delete(v.Email)
+		s.ports.Release(v.Port)
+	}
 
 	return nil
 }
 
-func (s *V2Ray) Peers() (items []types.Peer, err error) {
-	conn, client, err := s.statsServiceClient()
+// RekeyPeer replaces the key of a peer by removing it and adding the new one,
+// restoring the former when the new key cannot be added.
+func (s *V2Ray) RekeyPeer(data, newData []byte) ([]byte, error) {
+	if !s.HasPeer(data) {
+		return nil, fmt.Errorf("peer %s does not exist", base64.StdEncoding.EncodeToString(data))
+	}
+	if s.HasPeer(newData) {
+		return nil, fmt.Errorf("peer %s already exists", base64.StdEncoding.EncodeToString(newData))
+	}
+	if len(newData) != 1+16 {
+		return nil, errors.New("data length must be 17 bytes")
+	}
+
+	if err := s.RemovePeer(data); err != nil {
+		return nil, err
+	}
+
+	result, err := s.AddPeer(newData)
 	if err != nil {
+		_, _ = s.AddPeer(data)
 		return nil, err
 	}
 
-	defer func() {
-		if err = conn.Close(); err != nil {
-			panic(err)
-		}
-	}()
+	return result, nil
+}
 
+func (s *V2Ray) Peers() (items []types.Peer, err error) {
+	var emails []string
 	err = s.peers.Iterate(
 		func(key string, _ v2raytypes.Peer) (bool, error) {
-			req := &statscommand.GetStatsRequest{
-				Reset_: false,
-				Name:   fmt.Sprintf("user>>>%s>>>traffic>>>uplink", key),
-			}
+			emails = append(emails, key)
+			return false, nil
+		},
+	)
 
-			res, err := client.GetStats(context.TODO(), req)
-			if err != nil {
-				if !strings.Contains(err.Error(), "not found") {
-					return false, err
-				}
-			}
+	if err != nil {
+		return nil, err
+	}
 
-			upLink := res.GetStat()
-			if upLink == nil {
-				upLink = &statscommand.Stat{}
-			}
+	items, err = s.backend.Traffic(emails)
+	if err != nil {
+		return nil, err
+	}
 
-			req = &statscommand.GetStatsRequest{
-				Reset_: false,
-				Name:   fmt.Sprintf("user>>>%s>>>traffic>>>downlink", key),
-			}
+	// The stats carry no handshake, so a peer counts as active whenever its
+	// traffic has changed since the previous call.
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-			res, err = client.GetStats(context.TODO(), req)
-			if err != nil {
-				if !strings.Contains(err.Error(), "not found") {
-					return false, err
-				}
-			}
+	var (
+		now      = time.Now()
+		observed = make(map[string]activity, len(items))
+	)
 
-			downLink := res.GetStat()
-			if downLink == nil {
-				downLink = &statscommand.Stat{}
-			}
+	for i := range items {
+		traffic := items[i].Upload + items[i].Download
 
-			items = append(
-				items,
-				types.Peer{
-					Key:      key,
-					Upload:   upLink.GetValue(),
-					Download: downLink.GetValue(),
-				},
-			)
-			return false, nil
-		},
-	)
+		value, ok := s.activity[items[i].Key]
+		if !ok || value.traffic != traffic {
+			value = activity{traffic: traffic, at: now}
+		}
 
-	if err != nil {
-		return nil, err
+		observed[items[i].Key] = value
+		items[i].LastActivity = value.at
 	}
 
+	s.activity = observed
 	return items, nil
 }
 
 func (s *V2Ray) PeerCount() int {
 	return s.peers.Len()
 }
+
+func (s *V2Ray) Health() types.ServiceHealth {
+	return s.backend.Health()
+}
+
+func (s *V2Ray) PeerConfig(host string, data []byte) (*types.PeerConfig, error) {
+	if len(data) != 1+16 {
+		return nil, errors.New("data length must be 17 bytes")
+	}
+	if !s.HasPeer(data) {
+		return nil, fmt.Errorf("peer %s does not exist", base64.StdEncoding.EncodeToString(data))
+	}
+
+	uid, err := uuid.ParseBytes(data[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := v2raytypes.Proxy(data[0])
+
+	endpoint, err := s.endpoint(proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	peer := s.peers.Get(base64.StdEncoding.EncodeToString(data))
+	if peer.Port != 0 {
+		endpoint.Port = peer.Port
+	}
+
+	link, err := s.shareLink(proxy, endpoint, host, uid.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.PeerConfig{
+		Type:      s.Type(),
+		Host:      host,
+		Port:      endpoint.Port,
+		Protocol:  proxy.String(),
+		Transport: endpoint.Transport,
+		TLS:       endpoint.TLS,
+		Config:    link,
+	}, nil
+}
@@ -0,0 +1,15 @@
+package wireguard
+
+import (
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+// backend manages the WireGuard interface and its peers, identified by their
+// base64 encoded public keys.
+type backend interface {
+	Up() error
+	Down() error
+	AddPeer(identity string, allowedIPs []string) error
+	RemovePeer(identity string) error
+	Transfer() ([]types.Peer, error)
+}
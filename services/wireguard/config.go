@@ -15,3 +15,18 @@ PostUp = iptables -A FORWARD -i %i -j ACCEPT; iptables -t nat -A POSTROUTING -o
 PostDown = iptables -D FORWARD -i %i -j ACCEPT; iptables -t nat -D POSTROUTING -o eth0 -j MASQUERADE; ip6tables -D FORWARD -i %i -j ACCEPT; ip6tables -t nat -D POSTROUTING -o eth0 -j MASQUERADE;
     `)
 )
+
+// nolint:lll
+var (
+	clientConfigTemplate = strings.TrimSpace(`
+[Interface]
+Address = {{ .IPv4Address }}/32,{{ .IPv6Address }}/128
+PrivateKey = <client private key>
+
+[Peer]
+PublicKey = {{ .PublicKey }}
+AllowedIPs = 0.0.0.0/0,::/0
+Endpoint = {{ .Host }}:{{ .Port }}
+PersistentKeepalive = 15
+    `)
+)
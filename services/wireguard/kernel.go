@@ -0,0 +1,112 @@
+package wireguard
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+var (
+	_ backend = (*kernel)(nil)
+)
+
+// kernel drives the kernel module through the wg-quick and wg tools.
+type kernel struct {
+	iface string
+}
+
+func newKernel(iface string) *kernel {
+	return &kernel{
+		iface: iface,
+	}
+}
+
+func (b *kernel) Up() error {
+	cmd := exec.Command("wg-quick", strings.Split(
+		fmt.Sprintf("up %s", b.iface), " ")...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+func (b *kernel) Down() error {
+	cmd := exec.Command("wg-quick", strings.Split(
+		fmt.Sprintf("down %s", b.iface), " ")...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+func (b *kernel) AddPeer(identity string, allowedIPs []string) error {
+	cmd := exec.Command("wg", strings.Split(
+		fmt.Sprintf(`set %s peer %s allowed-ips %s`,
+			b.iface, identity, strings.Join(allowedIPs, ",")), " ")...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+func (b *kernel) RemovePeer(identity string) error {
+	cmd := exec.Command("wg", strings.Split(
+		fmt.Sprintf(`set %s peer %s remove`,
+			b.iface, identity), " ")...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// Transfer parses the peer lines of wg show dump, which carry the public key,
+// preshared key, endpoint, allowed IPs, latest handshake, received bytes, sent
+// bytes and persistent keepalive; the interface line has four columns only.
+func (b *kernel) Transfer() (items []types.Peer, err error) {
+	output, err := exec.Command("wg", strings.Split(
+		fmt.Sprintf("show %s dump", b.iface), " ")...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(output), "\n")
+	for _, line := range lines {
+		columns := strings.Split(line, "\t")
+		if len(columns) != 8 {
+			continue
+		}
+
+		handshake, err := strconv.ParseInt(columns[4], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		upload, err := strconv.ParseInt(columns[5], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		download, err := strconv.ParseInt(columns[6], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		item := types.Peer{
+			Key:      columns[0],
+			Upload:   upload,
+			Download: download,
+		}
+		if handshake > 0 {
+			item.LastActivity = time.Unix(handshake, 0)
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"fmt"
 	"os"
 	"strings"
 	"text/template"
@@ -20,6 +21,9 @@ interface = "{{ .Interface }}"
 # Port number to accept the incoming connections
 listen_port = {{ .ListenPort }}
 
+# Mode of running the interface; kernel (wg-quick) or userspace (wireguard-go in-process)
+mode = "{{ .Mode }}"
+
 # Server private key
 private_key = "{{ .PrivateKey }}"
 	`)
@@ -37,6 +41,7 @@ private_key = "{{ .PrivateKey }}"
 type Config struct {
 	Interface  string `json:"interface" mapstructure:"interface"`
 	ListenPort uint16 `json:"listen_port" mapstructure:"listen_port"`
+	Mode       string `json:"mode" mapstructure:"mode"`
 	PrivateKey string `json:"private_key" mapstructure:"private_key"`
 }
 
@@ -51,6 +56,9 @@ func (c *Config) Validate() error {
 	if c.ListenPort == 0 {
 		return errors.New("listen_port cannot be zero")
 	}
+	if c.Mode != ModeKernel && c.Mode != ModeUserspace {
+		return fmt.Errorf("invalid mode %s", c.Mode)
+	}
 	if c.PrivateKey == "" {
 		return errors.New("private_key cannot be empty")
 	}
@@ -69,11 +77,16 @@ func (c *Config) WithDefaultValues() *Config {
 
 	c.Interface = "wg0"
 	c.ListenPort = utils.RandomPort()
+	c.Mode = ModeKernel
 	c.PrivateKey = key.String()
 
 	return c
 }
 
+func (c *Config) Userspace() bool {
+	return c.Mode == ModeUserspace
+}
+
 func (c *Config) SaveToPath(path string) error {
 	var buffer bytes.Buffer
 	if err := t.Execute(&buffer, c); err != nil {
@@ -92,7 +105,11 @@ func (c *Config) String() string {
 	return buffer.String()
 }
 
-func ReadInConfig(v *viper.Viper) (*Config, error) {
+var (
+	configKeys = utils.ConfigKeys(Config{})
+)
+
+func ReadInConfigFile(v *viper.Viper) (*Config, error) {
 	config := NewConfig().WithDefaultValues()
 	if err := v.ReadInConfig(); err != nil {
 		return nil, err
@@ -103,3 +120,24 @@ func ReadInConfig(v *viper.Viper) (*Config, error) {
 
 	return config, nil
 }
+
+// ReadInConfig overrides the values of the configuration file with the
+// environment variables SENTINELNODE_WIREGUARD_<KEY>, or the <KEY>_FILE variants.
+func ReadInConfig(v *viper.Viper) (*Config, error) {
+	config, err := ReadInConfigFile(v)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.SetFromEnv(v, EnvPrefix, configKeys); err != nil {
+		return nil, err
+	}
+	if err = v.Unmarshal(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func EnvOverrides() ([]utils.EnvOverride, error) {
+	return utils.EnvOverrides(EnvPrefix, configKeys)
+}
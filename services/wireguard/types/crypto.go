@@ -4,6 +4,7 @@ import (
 	crand "crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 
 	"golang.org/x/crypto/curve25519"
@@ -63,6 +64,10 @@ func (k *Key) String() string {
 	return base64.StdEncoding.EncodeToString(k[:])
 }
 
+func (k *Key) Hex() string {
+	return hex.EncodeToString(k[:])
+}
+
 func (k *Key) IsZero() bool {
 	var zeros Key
 	return subtle.ConstantTimeCompare(zeros[:], k[:]) == 1
@@ -3,4 +3,10 @@ package types
 const (
 	Type           = 1
 	ConfigFileName = "wireguard.toml"
+	EnvPrefix      = "SENTINELNODE_WIREGUARD"
+)
+
+const (
+	ModeKernel    = "kernel"
+	ModeUserspace = "userspace"
 )
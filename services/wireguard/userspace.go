@@ -0,0 +1,235 @@
+package wireguard
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.zx2c4.com/wireguard/conn"
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun"
+
+	wgtypes "github.com/sentinel-official/dvpn-node/services/wireguard/types"
+	"github.com/sentinel-official/dvpn-node/types"
+)
+
+const (
+	// The interface addresses match the Address of configTemplate.
+	interfaceIPv4 = "10.8.0.1/24"
+	interfaceIPv6 = "fd86:ea04:1115::1/120"
+)
+
+var (
+	_ backend = (*userspace)(nil)
+)
+
+// userspace runs wireguard-go in-process on a TUN device, so neither the
+// kernel module nor the wg tools are required.
+type userspace struct {
+	sync.Mutex
+	config *wgtypes.Config
+	device *device.Device
+}
+
+func newUserspace(config *wgtypes.Config) *userspace {
+	return &userspace{
+		config: config,
+	}
+}
+
+func (b *userspace) run(commands ...string) error {
+	for _, command := range commands {
+		args := strings.Split(command, " ")
+
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *userspace) rules(action string) []string {
+	return []string{
+		fmt.Sprintf("iptables %s FORWARD -i %s -j ACCEPT", action, b.config.Interface),
+		fmt.Sprintf("iptables -t nat %s POSTROUTING -o eth0 -j MASQUERADE", action),
+		fmt.Sprintf("ip6tables %s FORWARD -i %s -j ACCEPT", action, b.config.Interface),
+		fmt.Sprintf("ip6tables -t nat %s POSTROUTING -o eth0 -j MASQUERADE", action),
+	}
+}
+
+func (b *userspace) Up() error {
+	key, err := wgtypes.KeyFromString(b.config.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	dev, err := tun.CreateTUN(b.config.Interface, device.DefaultMTU)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the tun device")
+	}
+
+	logger := device.NewLogger(device.LogLevelError, fmt.Sprintf("(%s) ", b.config.Interface))
+	d := device.NewDevice(dev, conn.NewDefaultBind(), logger)
+
+	if err = d.IpcSet(fmt.Sprintf("private_key=%s\nlisten_port=%d\n", key.Hex(), b.config.ListenPort)); err != nil {
+		d.Close()
+		return err
+	}
+	if err = d.Up(); err != nil {
+		d.Close()
+		return err
+	}
+
+	commands := []string{
+		fmt.Sprintf("ip -4 address add %s dev %s", interfaceIPv4, b.config.Interface),
+		fmt.Sprintf("ip -6 address add %s dev %s", interfaceIPv6, b.config.Interface),
+		fmt.Sprintf("ip link set mtu %d up dev %s", device.DefaultMTU, b.config.Interface),
+	}
+
+	if err = b.run(append(commands, b.rules("-A")...)...); err != nil {
+		d.Close()
+		return err
+	}
+
+	b.Lock()
+	b.device = d
+	b.Unlock()
+
+	return nil
+}
+
+func (b *userspace) Down() error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.device == nil {
+		return errors.New("device is nil")
+	}
+
+	b.device.Close()
+	b.device = nil
+
+	return b.run(b.rules("-D")...)
+}
+
+func (b *userspace) ipcSet(config string) error {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.device == nil {
+		return errors.New("device is nil")
+	}
+
+	return b.device.IpcSet(config)
+}
+
+func (b *userspace) AddPeer(identity string, allowedIPs []string) error {
+	key, err := wgtypes.KeyFromString(identity)
+	if err != nil {
+		return err
+	}
+
+	var config strings.Builder
+	config.WriteString(fmt.Sprintf("public_key=%s\nreplace_allowed_ips=true\n", key.Hex()))
+	for _, ip := range allowedIPs {
+		config.WriteString(fmt.Sprintf("allowed_ip=%s\n", ip))
+	}
+
+	return b.ipcSet(config.String())
+}
+
+func (b *userspace) RemovePeer(identity string) error {
+	key, err := wgtypes.KeyFromString(identity)
+	if err != nil {
+		return err
+	}
+
+	return b.ipcSet(fmt.Sprintf("public_key=%s\nremove=true\n", key.Hex()))
+}
+
+// Transfer parses the peers of the UAPI get operation, where each peer section
+// starts with its hex encoded public_key followed by last_handshake_time_sec,
+// rx_bytes and tx_bytes.
+func (b *userspace) Transfer() (items []types.Peer, err error) {
+	b.Lock()
+	if b.device == nil {
+		b.Unlock()
+		return nil, errors.New("device is nil")
+	}
+
+	output, err := b.device.IpcGet()
+	b.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "public_key":
+			buf, err := hex.DecodeString(value)
+			if err != nil {
+				return nil, err
+			}
+
+			pub, err := wgtypes.KeyFromBytes(buf)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items,
+				types.Peer{
+					Key: pub.String(),
+				},
+			)
+		case "last_handshake_time_sec":
+			if len(items) == 0 {
+				continue
+			}
+
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+
+			if v > 0 {
+				items[len(items)-1].LastActivity = time.Unix(v, 0)
+			}
+		case "rx_bytes", "tx_bytes":
+			if len(items) == 0 {
+				continue
+			}
+
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+
+			if key == "rx_bytes" {
+				items[len(items)-1].Upload = v
+			} else {
+				items[len(items)-1].Download = v
+			}
+		}
+	}
+
+	return items, nil
+}
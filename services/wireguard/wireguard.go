@@ -6,10 +6,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"strconv"
-	"strings"
 	"text/template"
 
 	"github.com/spf13/viper"
@@ -27,10 +24,11 @@ var (
 )
 
 type WireGuard struct {
-	info   []byte
-	config *wgtypes.Config
-	peers  *wgtypes.Peers
-	pool   *wgtypes.IPPool
+	info    []byte
+	config  *wgtypes.Config
+	peers   *wgtypes.Peers
+	pool    *wgtypes.IPPool
+	backend backend
 }
 
 func NewWireGuard(pool *wgtypes.IPPool) types.Service {
@@ -58,19 +56,25 @@ func (s *WireGuard) Init(home string) (err error) {
 		return err
 	}
 
-	t, err := template.New("wireguard_conf").Parse(configTemplate)
-	if err != nil {
-		return err
-	}
+	if s.config.Userspace() {
+		s.backend = newUserspace(s.config)
+	} else {
+		t, err := template.New("wireguard_conf").Parse(configTemplate)
+		if err != nil {
+			return err
+		}
 
-	var buffer bytes.Buffer
-	if err = t.Execute(&buffer, s.config); err != nil {
-		return err
-	}
+		var buffer bytes.Buffer
+		if err = t.Execute(&buffer, s.config); err != nil {
+			return err
+		}
 
-	path := fmt.Sprintf("/etc/wireguard/%s.conf", s.config.Interface)
-	if err = os.WriteFile(path, buffer.Bytes(), 0600); err != nil {
-		return err
+		path := fmt.Sprintf("/etc/wireguard/%s.conf", s.config.Interface)
+		if err = os.WriteFile(path, buffer.Bytes(), 0600); err != nil {
+			return err
+		}
+
+		s.backend = newKernel(s.config.Interface)
 	}
 
 	key, err := wgtypes.KeyFromString(s.config.PrivateKey)
@@ -89,21 +93,18 @@ func (s *WireGuard) Info() []byte {
 }
 
 func (s *WireGuard) Start() error {
-	cmd := exec.Command("wg-quick", strings.Split(
-		fmt.Sprintf("up %s", s.config.Interface), " ")...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	return cmd.Run()
+	return s.backend.Up()
 }
 
 func (s *WireGuard) Stop() error {
-	cmd := exec.Command("wg-quick", strings.Split(
-		fmt.Sprintf("down %s", s.config.Interface), " ")...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	return s.backend.Down()
+}
 
-	return cmd.Run()
+func allowedIPs(v4 wgtypes.IPv4, v6 wgtypes.IPv6) []string {
+	return []string{
+		fmt.Sprintf("%s/32", v4.IP()),
+		fmt.Sprintf("%s/128", v6.IP()),
+	}
 }
 
 func (s *WireGuard) AddPeer(data []byte) (result []byte, err error) {
@@ -120,13 +121,7 @@ func (s *WireGuard) AddPeer(data []byte) (result []byte, err error) {
 		}
 	}()
 
-	cmd := exec.Command("wg", strings.Split(
-		fmt.Sprintf(`set %s peer %s allowed-ips %s/32,%s/128`,
-			s.config.Interface, identity, v4.IP(), v6.IP()), " ")...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err = cmd.Run(); err != nil {
+	if err = s.backend.AddPeer(identity, allowedIPs(v4, v6)); err != nil {
 		return nil, err
 	}
 
@@ -155,13 +150,7 @@ func (s *WireGuard) HasPeer(data []byte) bool {
 func (s *WireGuard) RemovePeer(data []byte) error {
 	identity := base64.StdEncoding.EncodeToString(data)
 
-	cmd := exec.Command("wg", strings.Split(
-		fmt.Sprintf(`set %s peer %s remove`,
-			s.config.Interface, identity), " ")...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
+	if err := s.backend.RemovePeer(identity); err != nil {
 		return err
 	}
 
@@ -173,42 +162,105 @@ func (s *WireGuard) RemovePeer(data []byte) error {
 	return nil
 }
 
-func (s *WireGuard) Peers() (items []types.Peer, err error) {
-	output, err := exec.Command("wg", strings.Split(
-		fmt.Sprintf("show %s transfer", s.config.Interface), " ")...).Output()
-	if err != nil {
+// RekeyPeer replaces the public key of a peer, keeping its assigned addresses.
+func (s *WireGuard) RekeyPeer(data, newData []byte) (result []byte, err error) {
+	var (
+		identity    = base64.StdEncoding.EncodeToString(data)
+		newIdentity = base64.StdEncoding.EncodeToString(newData)
+		peer        = s.peers.Get(identity)
+	)
+
+	if peer.Empty() {
+		return nil, fmt.Errorf("peer %s does not exist", identity)
+	}
+	if !s.peers.Get(newIdentity).Empty() {
+		return nil, fmt.Errorf("peer %s already exists", newIdentity)
+	}
+	if _, err = wgtypes.KeyFromBytes(newData); err != nil {
 		return nil, err
 	}
 
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		columns := strings.Split(line, "\t")
-		if len(columns) != 3 {
-			continue
-		}
-
-		upload, err := strconv.ParseInt(columns[1], 10, 64)
-		if err != nil {
-			return nil, err
-		}
+	if err = s.backend.RemovePeer(identity); err != nil {
+		return nil, err
+	}
+	if err = s.backend.AddPeer(newIdentity, allowedIPs(peer.IPv4, peer.IPv6)); err != nil {
+		_ = s.backend.AddPeer(identity, allowedIPs(peer.IPv4, peer.IPv6))
+		return nil, err
+	}
 
-		download, err := strconv.ParseInt(columns[2], 10, 64)
-		if err != nil {
-			return nil, err
-		}
+	s.peers.Delete(identity)
+	s.peers.Put(
+		wgtypes.Peer{
+			Identity: newIdentity,
+			IPv4:     peer.IPv4,
+			IPv6:     peer.IPv6,
+		},
+	)
 
-		items = append(items,
-			types.Peer{
-				Key:      columns[0],
-				Upload:   upload,
-				Download: download,
-			},
-		)
-	}
+	result = append(result, peer.IPv4.Bytes()...)
+	result = append(result, peer.IPv6.Bytes()...)
+	return result, nil
+}
 
-	return items, nil
+func (s *WireGuard) Peers() ([]types.Peer, error) {
+	return s.backend.Transfer()
 }
 
 func (s *WireGuard) PeerCount() int {
 	return s.peers.Len()
 }
+
+func (s *WireGuard) Health() types.ServiceHealth {
+	return types.ServiceHealth{
+		Running:  true,
+		Restarts: 0,
+	}
+}
+
+func (s *WireGuard) Ports() []uint16 {
+	return []uint16{s.config.ListenPort}
+}
+
+func (s *WireGuard) Endpoints() []types.ServiceEndpoint {
+	return []types.ServiceEndpoint{
+		{
+			Protocol:  "wireguard",
+			Port:      s.config.ListenPort,
+			Transport: "udp",
+			TLS:       false,
+		},
+	}
+}
+
+func (s *WireGuard) PeerConfig(host string, data []byte) (*types.PeerConfig, error) {
+	var (
+		identity = base64.StdEncoding.EncodeToString(data)
+		peer     = s.peers.Get(identity)
+	)
+
+	if peer.Empty() {
+		return nil, fmt.Errorf("peer %s does not exist", identity)
+	}
+
+	config := &types.PeerConfig{
+		Type:        s.Type(),
+		IPv4Address: peer.IPv4.IP().String(),
+		IPv6Address: peer.IPv6.IP().String(),
+		Host:        host,
+		Port:        binary.BigEndian.Uint16(s.info[:2]),
+		PublicKey:   base64.StdEncoding.EncodeToString(s.info[2:]),
+	}
+
+	t, err := template.New("wireguard_client_conf").Parse(clientConfigTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err = t.Execute(&buffer, config); err != nil {
+		return nil, err
+	}
+
+	config.Config = buffer.String()
+	return config, nil
+}
@@ -0,0 +1,137 @@
+package types
+
+import (
+	"net/http"
+)
+
+type ErrorCode int
+
+// The unversioned routes keep returning the codes 1 to 10 of the errors they
+// have always returned, so the codes of the errors start from 101.
+const (
+	ErrorCodeUnknown                     ErrorCode = 0
+	ErrorCodeMaxPeersReached             ErrorCode = 101
+	ErrorCodeInvalidRequest              ErrorCode = 102
+	ErrorCodeSessionAlreadyExists        ErrorCode = 103
+	ErrorCodeKeyAlreadyExists            ErrorCode = 104
+	ErrorCodeAccountQueryFailed          ErrorCode = 105
+	ErrorCodeAccountNotFound             ErrorCode = 106
+	ErrorCodePubKeyNotFound              ErrorCode = 107
+	ErrorCodeInvalidSignature            ErrorCode = 108
+	ErrorCodeSessionQueryFailed          ErrorCode = 109
+	ErrorCodeSessionNotFound             ErrorCode = 110
+	ErrorCodeSessionInactive             ErrorCode = 111
+	ErrorCodeSessionAddressMismatch      ErrorCode = 112
+	ErrorCodeSubscriptionQueryFailed     ErrorCode = 113
+	ErrorCodeSubscriptionNotFound        ErrorCode = 114
+	ErrorCodeSubscriptionInactive        ErrorCode = 115
+	ErrorCodeSubscriptionInvalidType     ErrorCode = 116
+	ErrorCodeSubscriptionAddressMismatch ErrorCode = 117
+	ErrorCodeNodeAddressMismatch         ErrorCode = 118
+	ErrorCodePlanQueryFailed             ErrorCode = 119
+	ErrorCodeNodeNotInPlan               ErrorCode = 120
+	ErrorCodeAllocationQueryFailed       ErrorCode = 121
+	ErrorCodeAllocationNotFound          ErrorCode = 122
+	ErrorCodeAllocationExceeded          ErrorCode = 123
+	ErrorCodeRemovePeerFailed            ErrorCode = 124
+	ErrorCodeAddPeerFailed               ErrorCode = 125
+	ErrorCodePeerNotFound                ErrorCode = 126
+	ErrorCodePeersQueryFailed            ErrorCode = 127
+	ErrorCodeRequestTooLarge             ErrorCode = 128
+	ErrorCodeRateLimited                 ErrorCode = 129
+	ErrorCodeTooManyRequests             ErrorCode = 130
+	ErrorCodeBanned                      ErrorCode = 131
+	ErrorCodePeerConfigFailed            ErrorCode = 132
+	ErrorCodeServiceUnavailable          ErrorCode = 133
+	ErrorCodeServiceNotFound             ErrorCode = 134
+	ErrorCodeRekeyPeerFailed             ErrorCode = 135
+	ErrorCodeParamsQueryFailed           ErrorCode = 136
+	ErrorCodeInvalidPrices               ErrorCode = 137
+	ErrorCodeUpdatePricesFailed          ErrorCode = 138
+)
+
+type ErrorCodeInfo struct {
+	Code             ErrorCode `json:"code"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	HTTPStatus       int       `json:"http_status"`
+	Retryable        bool      `json:"retryable"`
+	LegacyCode       ErrorCode `json:"legacy_code,omitempty" description:"Code returned for the error on the unversioned routes"`
+	LegacyHTTPStatus int       `json:"legacy_http_status,omitempty" description:"HTTP status returned for the error on the unversioned routes"`
+}
+
+// nolint:lll
+var errorCodes = []ErrorCodeInfo{
+	{ErrorCodeUnknown, "unknown", "Unknown error", http.StatusInternalServerError, false, 0, 0},
+	{ErrorCodeMaxPeersReached, "max_peers_reached", "Node has reached the maximum number of concurrent peers", http.StatusServiceUnavailable, true, 1, http.StatusBadRequest},
+	{ErrorCodeInvalidRequest, "invalid_request", "Request URI, query or body is malformed", http.StatusBadRequest, false, 2, http.StatusBadRequest},
+	{ErrorCodeSessionAlreadyExists, "session_already_exists", "A peer for the session has already been added", http.StatusConflict, false, 3, http.StatusBadRequest},
+	{ErrorCodeKeyAlreadyExists, "key_already_exists", "The peer key is already in use by another session", http.StatusConflict, false, 3, http.StatusBadRequest},
+	{ErrorCodeAccountQueryFailed, "account_query_failed", "Failed to query the account from the chain", http.StatusBadGateway, true, 4, http.StatusInternalServerError},
+	{ErrorCodeAccountNotFound, "account_not_found", "Account does not exist on the chain", http.StatusNotFound, false, 4, http.StatusNotFound},
+	{ErrorCodePubKeyNotFound, "pub_key_not_found", "Account has no public key on the chain", http.StatusNotFound, false, 4, http.StatusNotFound},
+	{ErrorCodeInvalidSignature, "invalid_signature", "Signature verification failed", http.StatusUnauthorized, false, 4, http.StatusBadRequest},
+	{ErrorCodeSessionQueryFailed, "session_query_failed", "Failed to query the session from the chain", http.StatusBadGateway, true, 5, http.StatusInternalServerError},
+	{ErrorCodeSessionNotFound, "session_not_found", "Session does not exist", http.StatusNotFound, false, 5, http.StatusNotFound},
+	{ErrorCodeSessionInactive, "session_inactive", "Session status is not active", http.StatusBadRequest, false, 5, http.StatusNotFound},
+	{ErrorCodeSessionAddressMismatch, "session_address_mismatch", "Session does not belong to the account", http.StatusForbidden, false, 5, http.StatusBadRequest},
+	{ErrorCodeSubscriptionQueryFailed, "subscription_query_failed", "Failed to query the subscription from the chain", http.StatusBadGateway, true, 6, http.StatusInternalServerError},
+	{ErrorCodeSubscriptionNotFound, "subscription_not_found", "Subscription does not exist on the chain", http.StatusNotFound, false, 6, http.StatusNotFound},
+	{ErrorCodeSubscriptionInactive, "subscription_inactive", "Subscription status is not active", http.StatusBadRequest, false, 6, http.StatusBadRequest},
+	{ErrorCodeSubscriptionInvalidType, "subscription_invalid_type", "Subscription type is not supported", http.StatusBadRequest, false, 7, http.StatusBadRequest},
+	{ErrorCodeSubscriptionAddressMismatch, "subscription_address_mismatch", "Subscription does not belong to the account", http.StatusForbidden, false, 8, http.StatusBadRequest},
+	{ErrorCodeNodeAddressMismatch, "node_address_mismatch", "Subscription is for a different node", http.StatusForbidden, false, 7, http.StatusBadRequest},
+	{ErrorCodePlanQueryFailed, "plan_query_failed", "Failed to query the plan membership from the chain", http.StatusBadGateway, true, 7, http.StatusInternalServerError},
+	{ErrorCodeNodeNotInPlan, "node_not_in_plan", "Node does not belong to the subscription plan", http.StatusForbidden, false, 7, http.StatusBadRequest},
+	{ErrorCodeAllocationQueryFailed, "allocation_query_failed", "Failed to query the allocation from the chain", http.StatusBadGateway, true, 8, http.StatusInternalServerError},
+	{ErrorCodeAllocationNotFound, "allocation_not_found", "Allocation does not exist on the chain", http.StatusNotFound, false, 8, http.StatusNotFound},
+	{ErrorCodeAllocationExceeded, "allocation_exceeded", "Allocation has been fully utilised", http.StatusForbidden, false, 8, http.StatusBadRequest},
+	{ErrorCodeRemovePeerFailed, "remove_peer_failed", "Failed to remove an existing peer from the service", http.StatusInternalServerError, true, 9, http.StatusInternalServerError},
+	{ErrorCodeAddPeerFailed, "add_peer_failed", "Failed to add the peer to the service", http.StatusInternalServerError, true, 10, http.StatusInternalServerError},
+	{ErrorCodePeerNotFound, "peer_not_found", "No peer exists for the session on this node", http.StatusNotFound, false, 0, 0},
+	{ErrorCodePeersQueryFailed, "peers_query_failed", "Failed to query the peers from the service", http.StatusInternalServerError, true, 0, 0},
+	{ErrorCodeRequestTooLarge, "request_too_large", "Request body exceeds the maximum size", http.StatusRequestEntityTooLarge, false, 0, 0},
+	{ErrorCodeRateLimited, "rate_limited", "Request rate limit exceeded", http.StatusTooManyRequests, true, 0, 0},
+	{ErrorCodeTooManyRequests, "too_many_requests", "Too many concurrent requests are in flight", http.StatusServiceUnavailable, true, 0, 0},
+	{ErrorCodeBanned, "banned", "Client is temporarily banned after repeated signature failures", http.StatusForbidden, true, 0, 0},
+	{ErrorCodePeerConfigFailed, "peer_config_failed", "Failed to build the client configuration for the peer", http.StatusInternalServerError, false, 0, 0},
+	{ErrorCodeServiceUnavailable, "service_unavailable", "Service backend is not running and cannot accept peers", http.StatusServiceUnavailable, true, 0, 0},
+	{ErrorCodeServiceNotFound, "service_not_found", "Requested service type is not offered by the node", http.StatusBadRequest, false, 0, 0},
+	{ErrorCodeRekeyPeerFailed, "rekey_peer_failed", "Failed to replace the key of the peer in the service", http.StatusInternalServerError, true, 0, 0},
+	{ErrorCodeParamsQueryFailed, "params_query_failed", "Failed to query the node params from the chain", http.StatusBadGateway, true, 0, 0},
+	{ErrorCodeInvalidPrices, "invalid_prices", "Prices are malformed or outside the bounds of the node params", http.StatusBadRequest, false, 0, 0},
+	{ErrorCodeUpdatePricesFailed, "update_prices_failed", "Failed to broadcast the prices to the chain", http.StatusBadGateway, true, 0, 0},
+}
+
+func ErrorCodes() []ErrorCodeInfo {
+	return append([]ErrorCodeInfo{}, errorCodes...)
+}
+
+func (c ErrorCode) Info() ErrorCodeInfo {
+	for i := 0; i < len(errorCodes); i++ {
+		if errorCodes[i].Code == c {
+			return errorCodes[i]
+		}
+	}
+
+	return errorCodes[0]
+}
+
+func (c ErrorCode) HTTPStatus() int {
+	return c.Info().HTTPStatus
+}
+
+// Legacy returns the code and the HTTP status of the error on the unversioned
+// routes, which are the same as on the versioned ones for the newer errors.
+func (c ErrorCode) Legacy() (ErrorCode, int) {
+	info := c.Info()
+	if info.LegacyCode == 0 {
+		return info.Code, info.HTTPStatus
+	}
+
+	return info.LegacyCode, info.LegacyHTTPStatus
+}
+
+func (c ErrorCode) String() string {
+	return c.Info().Name
+}
@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"reflect"
 	"strings"
 	"text/template"
 	"time"
@@ -23,8 +24,11 @@ const (
 	MaxPeers                  = 250
 	MinMonikerLength          = 4
 	MaxMonikerLength          = 32
+	MinIdleTimeout            = 5 * time.Minute
 	MinIntervalSetSessions    = 2 * time.Second
 	MaxIntervalSetSessions    = 2 * time.Minute
+	MinIntervalUpdatePlans    = 1 * time.Minute
+	MaxIntervalUpdatePlans    = 1 * time.Hour
 	MinIntervalUpdateSessions = (1 * time.Hour) - (5 * time.Minute)
 	MaxIntervalUpdateSessions = (2 * time.Hour) - (5 * time.Minute)
 	MinIntervalUpdateStatus   = (30 * time.Minute) - (5 * time.Minute)
@@ -33,6 +37,34 @@ const (
 
 var (
 	ct = strings.TrimSpace(`
+[api]
+# Time duration for which an IP address is banned after repeated signature failures
+ban_duration = "{{ .API.BanDuration }}"
+
+# Number of signature failures from an IP address before it is banned
+ban_threshold = {{ .API.BanThreshold }}
+
+# Limit max size of the request body in bytes
+max_body_size = {{ .API.MaxBodySize }}
+
+# Limit max number of concurrent in-flight session add requests
+max_concurrent_session_adds = {{ .API.MaxConcurrentSessionAdds }}
+
+# Number of session add requests allowed per second for an account address
+account_rate_limit = {{ .API.AccountRateLimit }}
+
+# Max burst of session add requests allowed for an account address
+account_rate_burst = {{ .API.AccountRateBurst }}
+
+# Number of session add requests allowed per second for an IP address
+ip_rate_limit = {{ .API.IPRateLimit }}
+
+# Max burst of session add requests allowed for an IP address
+ip_rate_burst = {{ .API.IPRateBurst }}
+
+# Comma separated IP addresses or CIDRs of the reverse proxies whose forwarded client addresses are trusted
+trusted_proxies = "{{ .API.TrustedProxies }}"
+
 [chain]
 # Gas limit to set per transaction
 gas = {{ .Chain.Gas }}
@@ -76,6 +108,9 @@ from = "{{ .Keyring.From }}"
 # Time interval between each set_sessions operation
 interval_set_sessions = "{{ .Node.IntervalSetSessions }}"
 
+# Time interval between each update of the plans the node belongs to
+interval_update_plans = "{{ .Node.IntervalUpdatePlans }}"
+
 # Time interval between each update_sessions transaction
 interval_update_sessions = "{{ .Node.IntervalUpdateSessions }}"
 
@@ -100,12 +135,21 @@ hourly_prices = "{{ .Node.HourlyPrices }}"
 # Public URL of the node
 remote_url = "{{ .Node.RemoteURL }}"
 
-# Type of node
+# Comma separated list of the services offered by the node; wireguard, v2ray, openvpn and proxy (the first is the default)
 type = "{{ .Node.Type }}"
 
 [qos]
-# Limit max number of concurrent peers
+# Remove the peers inactive for the duration from the services, keeping their sessions; 0s to disable
+idle_timeout = "{{ .QOS.IdleTimeout }}"
+
+# Limit max number of concurrent peers (devices) of an account within a subscription; the oldest is evicted beyond it
+max_devices_per_account = {{ .QOS.MaxDevicesPerAccount }}
+
+# Limit max number of concurrent peers across all the services
 max_peers = {{ .QOS.MaxPeers }}
+
+# Limit max number of concurrent peers of each service; 0 to disable
+max_peers_per_service = {{ .QOS.MaxPeersPerService }}
 	`)
 
 	t = func() *template.Template {
@@ -118,6 +162,85 @@ max_peers = {{ .QOS.MaxPeers }}
 	}()
 )
 
+type APIConfig struct {
+	BanDuration              time.Duration `json:"ban_duration" mapstructure:"ban_duration"`
+	BanThreshold             int           `json:"ban_threshold" mapstructure:"ban_threshold"`
+	MaxBodySize              int64         `json:"max_body_size" mapstructure:"max_body_size"`
+	MaxConcurrentSessionAdds int           `json:"max_concurrent_session_adds" mapstructure:"max_concurrent_session_adds"`
+	AccountRateLimit         float64       `json:"account_rate_limit" mapstructure:"account_rate_limit"`
+	AccountRateBurst         int           `json:"account_rate_burst" mapstructure:"account_rate_burst"`
+	IPRateLimit              float64       `json:"ip_rate_limit" mapstructure:"ip_rate_limit"`
+	IPRateBurst              int           `json:"ip_rate_burst" mapstructure:"ip_rate_burst"`
+	TrustedProxies           string        `json:"trusted_proxies" mapstructure:"trusted_proxies"`
+}
+
+func NewAPIConfig() *APIConfig {
+	return &APIConfig{}
+}
+
+func (c *APIConfig) Validate() error {
+	if c.BanDuration < 0 {
+		return errors.New("ban_duration cannot be negative")
+	}
+	if c.BanThreshold <= 0 {
+		return errors.New("ban_threshold must be positive")
+	}
+	if c.MaxBodySize <= 0 {
+		return errors.New("max_body_size must be positive")
+	}
+	if c.MaxConcurrentSessionAdds <= 0 {
+		return errors.New("max_concurrent_session_adds must be positive")
+	}
+	if c.AccountRateLimit <= 0 {
+		return errors.New("account_rate_limit must be positive")
+	}
+	if c.AccountRateBurst <= 0 {
+		return errors.New("account_rate_burst must be positive")
+	}
+	if c.IPRateLimit <= 0 {
+		return errors.New("ip_rate_limit must be positive")
+	}
+	if c.IPRateBurst <= 0 {
+		return errors.New("ip_rate_burst must be positive")
+	}
+
+	for _, v := range c.TrustedProxyList() {
+		if net.ParseIP(v) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(v); err != nil {
+			return fmt.Errorf("invalid trusted_proxy %s", v)
+		}
+	}
+
+	return nil
+}
+
+// TrustedProxyList returns nil when no proxy is trusted, the client address
+// being the remote address of the connection then.
+func (c *APIConfig) TrustedProxyList() (items []string) {
+	for _, v := range strings.Split(c.TrustedProxies, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			items = append(items, v)
+		}
+	}
+
+	return items
+}
+
+func (c *APIConfig) WithDefaultValues() *APIConfig {
+	c.BanDuration = 10 * time.Minute
+	c.BanThreshold = 5
+	c.MaxBodySize = 4096
+	c.MaxConcurrentSessionAdds = 16
+	c.AccountRateLimit = 0.2
+	c.AccountRateBurst = 3
+	c.IPRateLimit = 1
+	c.IPRateBurst = 5
+
+	return c
+}
+
 type ChainConfig struct {
 	Gas                uint64  `json:"gas" mapstructure:"gas"`
 	GasAdjustment      float64 `json:"gas_adjustment" mapstructure:"gas_adjustment"`
@@ -245,6 +368,7 @@ func (c *KeyringConfig) WithDefaultValues() *KeyringConfig {
 
 type NodeConfig struct {
 	IntervalSetSessions    time.Duration `json:"interval_set_sessions" mapstructure:"interval_set_sessions"`
+	IntervalUpdatePlans    time.Duration `json:"interval_update_plans" mapstructure:"interval_update_plans"`
 	IntervalUpdateSessions time.Duration `json:"interval_update_sessions" mapstructure:"interval_update_sessions"`
 	IntervalUpdateStatus   time.Duration `json:"interval_update_status" mapstructure:"interval_update_status"`
 	IPv4Address            string        `json:"ipv4_address" mapstructure:"ipv4_address"`
@@ -267,6 +391,12 @@ func (c *NodeConfig) Validate() error {
 	if c.IntervalSetSessions > MaxIntervalSetSessions {
 		return fmt.Errorf("interval_set_sessions cannot be greater than %s", MaxIntervalSetSessions)
 	}
+	if c.IntervalUpdatePlans < MinIntervalUpdatePlans {
+		return fmt.Errorf("interval_update_plans cannot be less than %s", MinIntervalUpdatePlans)
+	}
+	if c.IntervalUpdatePlans > MaxIntervalUpdatePlans {
+		return fmt.Errorf("interval_update_plans cannot be greater than %s", MaxIntervalUpdatePlans)
+	}
 	if c.IntervalUpdateSessions < MinIntervalUpdateSessions {
 		return fmt.Errorf("interval_update_sessions cannot be less than %s", MinIntervalUpdateSessions)
 	}
@@ -330,15 +460,43 @@ func (c *NodeConfig) Validate() error {
 	if c.Type == "" {
 		return errors.New("type cannot be empty")
 	}
-	if c.Type != "wireguard" && c.Type != "v2ray" {
-		return errors.New("type must be either wireguard or v2ray")
+
+	types := make(map[string]bool)
+	for _, v := range c.Types() {
+		if v != "wireguard" && v != "v2ray" && v != "openvpn" && v != "proxy" {
+			return errors.New("type must be one of wireguard, v2ray, openvpn or proxy")
+		}
+		if types[v] {
+			return fmt.Errorf("duplicate type %s", v)
+		}
+
+		types[v] = true
 	}
 
 	return nil
 }
 
+func (c *NodeConfig) Types() (items []string) {
+	for _, v := range strings.Split(c.Type, ",") {
+		items = append(items, strings.TrimSpace(v))
+	}
+
+	return items
+}
+
+func (c *NodeConfig) HasType(v string) bool {
+	for _, t := range c.Types() {
+		if t == v {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (c *NodeConfig) WithDefaultValues() *NodeConfig {
 	c.IntervalSetSessions = 10 * time.Second
+	c.IntervalUpdatePlans = 15 * time.Minute
 	c.IntervalUpdateSessions = MaxIntervalUpdateSessions
 	c.IntervalUpdateStatus = MaxIntervalUpdateStatus
 	c.ListenOn = fmt.Sprintf("0.0.0.0:%d", utils.RandomPort())
@@ -348,7 +506,10 @@ func (c *NodeConfig) WithDefaultValues() *NodeConfig {
 }
 
 type QOSConfig struct {
-	MaxPeers int `json:"max_peers" mapstructure:"max_peers"`
+	IdleTimeout          time.Duration `json:"idle_timeout" mapstructure:"idle_timeout"`
+	MaxDevicesPerAccount int           `json:"max_devices_per_account" mapstructure:"max_devices_per_account"`
+	MaxPeers             int           `json:"max_peers" mapstructure:"max_peers"`
+	MaxPeersPerService   int           `json:"max_peers_per_service" mapstructure:"max_peers_per_service"`
 }
 
 func NewQOSConfig() *QOSConfig {
@@ -356,23 +517,45 @@ func NewQOSConfig() *QOSConfig {
 }
 
 func (c *QOSConfig) Validate() error {
+	if c.IdleTimeout < 0 {
+		return errors.New("idle_timeout cannot be negative")
+	}
+	if c.IdleTimeout > 0 && c.IdleTimeout < MinIdleTimeout {
+		return fmt.Errorf("idle_timeout cannot be less than %s", MinIdleTimeout)
+	}
+	if c.MaxDevicesPerAccount < 1 {
+		return errors.New("max_devices_per_account cannot be less than 1")
+	}
+	if c.MaxDevicesPerAccount > c.MaxPeers {
+		return errors.New("max_devices_per_account cannot be greater than max_peers")
+	}
 	if c.MaxPeers < MinPeers {
 		return fmt.Errorf("max_peers cannot be less than %d", MinPeers)
 	}
 	if c.MaxPeers > MaxPeers {
 		return fmt.Errorf("max_peers cannot be greater than %d", MaxPeers)
 	}
+	if c.MaxPeersPerService < 0 {
+		return errors.New("max_peers_per_service cannot be negative")
+	}
+	if c.MaxPeersPerService > c.MaxPeers {
+		return errors.New("max_peers_per_service cannot be greater than max_peers")
+	}
 
 	return nil
 }
 
 func (c *QOSConfig) WithDefaultValues() *QOSConfig {
+	c.IdleTimeout = 0
+	c.MaxDevicesPerAccount = 1
 	c.MaxPeers = MaxPeers
+	c.MaxPeersPerService = 0
 
 	return c
 }
 
 type Config struct {
+	API       *APIConfig       `json:"api" mapstructure:"api"`
 	Chain     *ChainConfig     `json:"chain" mapstructure:"chain"`
 	Handshake *HandshakeConfig `json:"handshake" mapstructure:"handshake"`
 	Keyring   *KeyringConfig   `json:"keyring" mapstructure:"keyring"`
@@ -382,6 +565,7 @@ type Config struct {
 
 func NewConfig() *Config {
 	return &Config{
+		API:       NewAPIConfig(),
 		Chain:     NewChainConfig(),
 		Handshake: NewHandshakeConfig(),
 		Keyring:   NewKeyringConfig(),
@@ -391,6 +575,9 @@ func NewConfig() *Config {
 }
 
 func (c *Config) Validate() error {
+	if err := c.API.Validate(); err != nil {
+		return errors.Wrapf(err, "invalid section api")
+	}
 	if err := c.Chain.Validate(); err != nil {
 		return errors.Wrapf(err, "invalid section chain")
 	}
@@ -407,7 +594,7 @@ func (c *Config) Validate() error {
 		return errors.Wrapf(err, "invalid section qos")
 	}
 
-	if c.Node.Type == "v2ray" {
+	if c.Node.HasType("v2ray") {
 		if c.Handshake.Enable {
 			return errors.Wrapf(errors.New("must be disabled"), "invalid section handshake")
 		}
@@ -417,6 +604,7 @@ func (c *Config) Validate() error {
 }
 
 func (c *Config) WithDefaultValues() *Config {
+	c.API = c.API.WithDefaultValues()
 	c.Chain = c.Chain.WithDefaultValues()
 	c.Handshake = c.Handshake.WithDefaultValues()
 	c.Keyring = c.Keyring.WithDefaultValues()
@@ -444,7 +632,13 @@ func (c *Config) String() string {
 	return buf.String()
 }
 
-func ReadInConfig(v *viper.Viper) (*Config, error) {
+var (
+	configKeys = utils.ConfigKeys(Config{})
+)
+
+// ReadInConfigFile reads the configuration file alone, for the commands
+// saving the configuration back to it.
+func ReadInConfigFile(v *viper.Viper) (*Config, error) {
 	config := NewConfig().WithDefaultValues()
 	if err := v.ReadInConfig(); err != nil {
 		return nil, err
@@ -455,3 +649,67 @@ func ReadInConfig(v *viper.Viper) (*Config, error) {
 
 	return config, nil
 }
+
+// ReadInConfig reads the configuration file with the values overridden by
+// the environment variables SENTINELNODE_<KEY>, or SENTINELNODE_<KEY>_FILE naming a file
+// with the value, the key being upper-cased with the dots as underscores.
+func ReadInConfig(v *viper.Viper) (*Config, error) {
+	config, err := ReadInConfigFile(v)
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.SetFromEnv(v, EnvPrefix, configKeys); err != nil {
+		return nil, err
+	}
+	if err = v.Unmarshal(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// EnvOverrides lists the configuration keys overridden by the environment.
+func EnvOverrides() ([]utils.EnvOverride, error) {
+	return utils.EnvOverrides(EnvPrefix, configKeys)
+}
+
+// ConfigChange is a changed value of the configuration, keyed by the section
+// and the field, e.g. qos.max_peers.
+type ConfigChange struct {
+	Key string
+	Old interface{}
+	New interface{}
+}
+
+// Diff lists the values of the sections that differ in v.
+func (c *Config) Diff(v *Config) (items []ConfigChange) {
+	var (
+		x = reflect.ValueOf(c).Elem()
+		y = reflect.ValueOf(v).Elem()
+	)
+
+	for i := 0; i < x.NumField(); i++ {
+		var (
+			section = x.Type().Field(i).Tag.Get("json")
+			xs      = x.Field(i).Elem()
+			ys      = y.Field(i).Elem()
+		)
+
+		for j := 0; j < xs.NumField(); j++ {
+			var (
+				xv = xs.Field(j).Interface()
+				yv = ys.Field(j).Interface()
+			)
+
+			if xv != yv {
+				items = append(items, ConfigChange{
+					Key: section + "." + xs.Type().Field(j).Tag.Get("json"),
+					Old: xv,
+					New: yv,
+				})
+			}
+		}
+	}
+
+	return items
+}
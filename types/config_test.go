@@ -0,0 +1,76 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func cloneConfig(c *Config) *Config {
+	var (
+		api       = *c.API
+		chain     = *c.Chain
+		handshake = *c.Handshake
+		keyring   = *c.Keyring
+		node      = *c.Node
+		qos       = *c.QOS
+	)
+
+	return &Config{
+		API:       &api,
+		Chain:     &chain,
+		Handshake: &handshake,
+		Keyring:   &keyring,
+		Node:      &node,
+		QOS:       &qos,
+	}
+}
+
+func TestConfigDiff(t *testing.T) {
+	config := NewConfig().WithDefaultValues()
+
+	tests := []struct {
+		name   string
+		update func(c *Config)
+		want   []ConfigChange
+	}{
+		{
+			name:   "unchanged",
+			update: func(*Config) {},
+			want:   nil,
+		},
+		{
+			name: "single value",
+			update: func(c *Config) {
+				c.QOS.MaxPeers = config.QOS.MaxPeers + 1
+			},
+			want: []ConfigChange{
+				{Key: "qos.max_peers", Old: config.QOS.MaxPeers, New: config.QOS.MaxPeers + 1},
+			},
+		},
+		{
+			name: "values of several sections in order",
+			update: func(c *Config) {
+				c.Node.Moniker = "moniker"
+				c.Chain.GasPrices = "1udvpn"
+				c.QOS.IdleTimeout = time.Hour
+			},
+			want: []ConfigChange{
+				{Key: "chain.gas_prices", Old: config.Chain.GasPrices, New: "1udvpn"},
+				{Key: "node.moniker", Old: config.Node.Moniker, New: "moniker"},
+				{Key: "qos.idle_timeout", Old: config.QOS.IdleTimeout, New: time.Hour},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := cloneConfig(config)
+			tc.update(v)
+
+			if got := config.Diff(v); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
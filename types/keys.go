@@ -8,7 +8,9 @@ import (
 const (
 	ConfigFileName   = "config.toml"
 	ContentType      = "application/json; charset=utf-8"
+	ContentTypeV1    = "application/vnd.sentinel.v1+json"
 	DatabaseFileName = "data.db"
+	EnvPrefix        = "SENTINELNODE"
 	IPv4CIDR         = "10.8.0.2/24"
 	IPv6CIDR         = "fd86:ea04:1115::2/120"
 	KeyringName      = "sentinel"
@@ -18,6 +20,11 @@ const (
 	FlagForce = "force"
 )
 
+const (
+	ContextKeyAPIVersion       = "api_version"
+	ContextKeyInvalidSignature = "invalid_signature"
+)
+
 var (
 	DefaultHomeDirectory = func() string {
 		home, err := os.UserHomeDir()
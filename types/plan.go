@@ -0,0 +1,73 @@
+package types
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Plans is the in-memory set of the plans the node belongs to.
+type Plans struct {
+	sync.RWMutex
+	m         map[uint64]struct{}
+	updatedAt time.Time
+}
+
+func NewPlans() *Plans {
+	return &Plans{
+		m: make(map[uint64]struct{}),
+	}
+}
+
+// Set replaces the set with the given plan IDs.
+func (p *Plans) Set(ids []uint64) {
+	m := make(map[uint64]struct{}, len(ids))
+	for _, id := range ids {
+		m[id] = struct{}{}
+	}
+
+	p.Lock()
+	defer p.Unlock()
+
+	p.m = m
+	p.updatedAt = time.Now()
+}
+
+func (p *Plans) Add(id uint64) {
+	p.Lock()
+	defer p.Unlock()
+
+	p.m[id] = struct{}{}
+}
+
+func (p *Plans) Has(id uint64) bool {
+	p.RLock()
+	defer p.RUnlock()
+
+	_, ok := p.m[id]
+	return ok
+}
+
+// IDs returns the plan IDs in ascending order.
+func (p *Plans) IDs() []uint64 {
+	p.RLock()
+	defer p.RUnlock()
+
+	items := make([]uint64, 0, len(p.m))
+	for id := range p.m {
+		items = append(items, id)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i] < items[j]
+	})
+
+	return items
+}
+
+func (p *Plans) UpdatedAt() time.Time {
+	p.RLock()
+	defer p.RUnlock()
+
+	return p.updatedAt
+}
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// ValidatePrices checks the prices against the bounds of the node params the
+// same way the chain does, where a denom missing in the prices counts as zero.
+// The empty prices are left unchanged by the chain and are not checked.
+func ValidatePrices(prices, minPrices, maxPrices sdk.Coins) error {
+	if prices.Empty() {
+		return nil
+	}
+
+	for _, coin := range maxPrices {
+		if amount := prices.AmountOf(coin.Denom); amount.GT(coin.Amount) {
+			return fmt.Errorf("price %s%s is greater than the maximum %s", amount, coin.Denom, coin)
+		}
+	}
+	for _, coin := range minPrices {
+		if amount := prices.AmountOf(coin.Denom); amount.LT(coin.Amount) {
+			return fmt.Errorf("price %s%s is less than the minimum %s", amount, coin.Denom, coin)
+		}
+	}
+
+	return nil
+}
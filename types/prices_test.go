@@ -0,0 +1,44 @@
+package types
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+func TestValidatePrices(t *testing.T) {
+	tests := []struct {
+		name      string
+		prices    string
+		minPrices string
+		maxPrices string
+		wantErr   bool
+	}{
+		{"empty prices are not checked", "", "10udvpn", "100udvpn", false},
+		{"within the bounds", "50udvpn", "10udvpn", "100udvpn", false},
+		{"equal to the bounds", "10udvpn", "10udvpn", "10udvpn", false},
+		{"no bounds", "50udvpn", "", "", false},
+		{"greater than the maximum", "101udvpn", "10udvpn", "100udvpn", true},
+		{"less than the minimum", "9udvpn", "10udvpn", "100udvpn", true},
+		{"missing denom counts as zero", "50uatom", "10udvpn", "", true},
+		{"denom without a maximum", "1000uatom", "", "100udvpn", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			coins := func(s string) sdk.Coins {
+				v, err := sdk.ParseCoinsNormalized(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				return v
+			}
+
+			err := ValidatePrices(coins(tc.prices), coins(tc.minPrices), coins(tc.maxPrices))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error %t, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
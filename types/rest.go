@@ -1,11 +1,11 @@
 package types
 
 type Error struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
 }
 
-func NewError(code int, message string) *Error {
+func NewError(code ErrorCode, message string) *Error {
 	return &Error{
 		Code:    code,
 		Message: message,
@@ -26,7 +26,7 @@ func NewResponse(err interface{}, res interface{}) *Response {
 	}
 }
 
-func NewResponseError(code int, v interface{}) *Response {
+func NewResponseError(code ErrorCode, v interface{}) *Response {
 	message := "unknown error"
 	if m, ok := v.(string); ok {
 		message = m
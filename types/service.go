@@ -1,5 +1,10 @@
 package types
 
+import (
+	"fmt"
+	"time"
+)
+
 type Service interface {
 	Type() uint64
 	Info() []byte
@@ -9,12 +14,126 @@ type Service interface {
 	AddPeer(data []byte) ([]byte, error)
 	HasPeer(data []byte) bool
 	RemovePeer(data []byte) error
+	RekeyPeer(data, newData []byte) ([]byte, error)
 	Peers() ([]Peer, error)
 	PeerCount() int
+	PeerConfig(host string, data []byte) (*PeerConfig, error)
+	Health() ServiceHealth
+	Endpoints() []ServiceEndpoint
+	Ports() []uint16
+}
+
+type ServiceHealth struct {
+	Running  bool   `json:"running" description:"Whether the service backend is running and accepting peers"`
+	Restarts uint64 `json:"restarts" description:"Number of times the service backend has been restarted"`
 }
 
 type Peer struct {
-	Key      string `json:"key"`
-	Upload   int64  `json:"upload"`
-	Download int64  `json:"download"`
+	Key          string    `json:"key"`
+	Upload       int64     `json:"upload"`
+	Download     int64     `json:"download"`
+	LastActivity time.Time `json:"last_activity"`
+}
+
+type PeerConfig struct {
+	Type        uint64 `json:"type" description:"Service type; 1 for WireGuard, 2 for V2Ray, 3 for OpenVPN, 4 for proxy"`
+	IPv4Address string `json:"ipv4_address,omitempty" description:"IPv4 address assigned to the peer"`
+	IPv6Address string `json:"ipv6_address,omitempty" description:"IPv6 address assigned to the peer"`
+	Host        string `json:"host" description:"Endpoint host of the service"`
+	Port        uint16 `json:"port" description:"Endpoint port of the service"`
+	PublicKey   string `json:"public_key,omitempty" description:"Base64 encoded public key of the server"`
+	Protocol    string `json:"protocol,omitempty" description:"Proxy protocol of the peer"`
+	Transport   string `json:"transport,omitempty" description:"Transport protocol of the service"`
+	TLS         bool   `json:"tls" description:"Whether TLS is enabled on the endpoint"`
+	Config      string `json:"config" description:"Client configuration; a wg-quick file, a V2Ray share link, an OpenVPN profile or proxy URLs"`
+}
+
+type ServiceEndpoint struct {
+	Protocol    string   `json:"protocol" description:"Protocol accepted on the endpoint"`
+	Port        uint16   `json:"port" description:"Port number of the endpoint"`
+	Transport   string   `json:"transport" description:"Transport protocol of the endpoint"`
+	TLS         bool     `json:"tls" description:"Whether TLS is enabled on the endpoint"`
+	ALPN        []string `json:"alpn,omitempty" description:"Application protocols advertised over TLS"`
+	HeaderType  string   `json:"header_type,omitempty" description:"Packet header type for the mkcp and quic transports"`
+	Host        string   `json:"host,omitempty" description:"Host header for the websocket and http transports"`
+	Path        string   `json:"path,omitempty" description:"Request path for the websocket and http transports"`
+	ServerName  string   `json:"server_name,omitempty" description:"Server name (SNI) of the TLS certificate"`
+	ServiceName string   `json:"service_name,omitempty" description:"Service name for the grpc and gun transports"`
+}
+
+type Services struct {
+	m     map[uint64]Service
+	items []Service
+}
+
+func NewServices() *Services {
+	return &Services{
+		m: make(map[uint64]Service),
+	}
+}
+
+func (s *Services) Add(v Service) error {
+	if _, ok := s.m[v.Type()]; ok {
+		return fmt.Errorf("service type %d already exists", v.Type())
+	}
+
+	s.m[v.Type()] = v
+	s.items = append(s.items, v)
+
+	return nil
+}
+
+// Get returns the service of the given type. The zero type of the sessions
+// stored before the services were typed resolves only while a single service
+// is configured, since the order of node.type says nothing about them.
+func (s *Services) Get(t uint64) Service {
+	if t == 0 {
+		if len(s.items) != 1 {
+			return nil
+		}
+
+		return s.items[0]
+	}
+
+	return s.m[t]
+}
+
+func (s *Services) Default() Service {
+	if len(s.items) == 0 {
+		return nil
+	}
+
+	return s.items[0]
+}
+
+func (s *Services) Items() []Service {
+	return s.items
+}
+
+func (s *Services) Len() int {
+	return len(s.items)
+}
+
+// ValidatePorts returns an error if any two services listen on the same port.
+func (s *Services) ValidatePorts() error {
+	m := make(map[uint16]uint64)
+	for _, v := range s.items {
+		for _, port := range v.Ports() {
+			if t, ok := m[port]; ok && t != v.Type() {
+				return fmt.Errorf("port %d is used by both service types %d and %d", port, t, v.Type())
+			}
+
+			m[port] = v.Type()
+		}
+	}
+
+	return nil
+}
+
+func (s *Services) PeerCount() (count int) {
+	for _, v := range s.items {
+		count += v.PeerCount()
+	}
+
+	return count
 }
@@ -11,9 +11,21 @@ type Session struct {
 	Subscription uint64 `gorm:"index:idx_sessions_subscription_address"`
 	Key          string `gorm:"uniqueIndex:idx_sessions_key"`
 	Address      string `gorm:"index:idx_sessions_address;index:idx_sessions_subscription_address"`
+	Type         uint64
 	Available    int64
 	Download     int64
 	Upload       int64
+
+	// Utilised holds the usage of the sessions of the account within the
+	// subscription when the peer was added, which Available excludes. On a
+	// reconnect it includes the usage of this session so far.
+	Utilised int64
+
+	// BaseDownload and BaseUpload hold the usage accumulated under the
+	// previous keys of a rekeyed session, on top of which the service
+	// counters of the current key are added.
+	BaseDownload int64
+	BaseUpload   int64
 }
 
 func (s *Session) GetAddress() sdk.AccAddress {
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// EnvOverride is a configuration key along with the environment variable
+// overriding it.
+type EnvOverride struct {
+	Key string
+	Env string
+}
+
+// ConfigKeys lists the keys of the configuration struct v from the
+// mapstructure tags, skipping the lists of the sections.
+func ConfigKeys(v interface{}) []string {
+	return configKeys(reflect.TypeOf(v), "")
+}
+
+func configKeys(t reflect.Type, prefix string) (keys []string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			field   = t.Field(i)
+			name    = strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+			squash  = strings.HasSuffix(field.Tag.Get("mapstructure"), ",squash")
+			element = field.Type
+		)
+
+		for element.Kind() == reflect.Ptr {
+			element = element.Elem()
+		}
+
+		switch {
+		case squash:
+			keys = append(keys, configKeys(element, prefix)...)
+		case name == "":
+			continue
+		case element.Kind() == reflect.Struct:
+			keys = append(keys, configKeys(element, prefix+name+".")...)
+		case element.Kind() == reflect.Slice && element.Elem().Kind() != reflect.String:
+			continue
+		default:
+			keys = append(keys, prefix+name)
+		}
+	}
+
+	return keys
+}
+
+// EnvName returns the environment variable of the key, e.g. PREFIX_NODE_MONIKER
+// for node.moniker.
+func EnvName(prefix, key string) string {
+	return prefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// lookupEnv returns the value of the environment variable of the key, or the
+// contents of the file named by the variable with the _FILE suffix.
+func lookupEnv(prefix, key string) (env, value string, ok bool, err error) {
+	name := EnvName(prefix, key)
+
+	value, ok = os.LookupEnv(name)
+	path, fileOK := os.LookupEnv(name + "_FILE")
+	if !fileOK {
+		return name, value, ok, nil
+	}
+	if ok {
+		return "", "", false, fmt.Errorf("both %s and %s_FILE are set", name, name)
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	return name + "_FILE", strings.TrimRight(string(buf), "\r\n"), true, nil
+}
+
+// SetFromEnv sets the keys with the environment variables set on v.
+func SetFromEnv(v *viper.Viper, prefix string, keys []string) error {
+	for _, key := range keys {
+		_, value, ok, err := lookupEnv(prefix, key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			v.Set(key, value)
+		}
+	}
+
+	return nil
+}
+
+// EnvOverrides lists the keys with the environment variables set.
+func EnvOverrides(prefix string, keys []string) (items []EnvOverride, err error) {
+	for _, key := range keys {
+		env, _, ok, err := lookupEnv(prefix, key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			items = append(items, EnvOverride{Key: key, Env: env})
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigKeys(t *testing.T) {
+	type inbound struct {
+		Port uint16 `mapstructure:"port"`
+	}
+	type section struct {
+		Enable bool `mapstructure:"enable"`
+		Port   uint16
+	}
+	type base struct {
+		Tag string `mapstructure:"tag"`
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want []string
+	}{
+		{
+			name: "flat fields",
+			v: struct {
+				A string `mapstructure:"a"`
+				B int    `mapstructure:"b"`
+			}{},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "nested sections and pointers",
+			v: &struct {
+				Node *section `mapstructure:"node"`
+				QOS  section  `mapstructure:"qos"`
+			}{},
+			want: []string{"node.enable", "qos.enable"},
+		},
+		{
+			name: "squashed fields",
+			v: struct {
+				base    `mapstructure:",squash"`
+				Enabled bool `mapstructure:"enabled"`
+			}{},
+			want: []string{"tag", "enabled"},
+		},
+		{
+			name: "lists of sections are skipped",
+			v: struct {
+				Inbounds []*inbound `mapstructure:"inbounds"`
+				Peers    []string   `mapstructure:"peers"`
+			}{},
+			want: []string{"peers"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ConfigKeys(tc.v); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLookupEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantEnv string
+		value   string
+		ok      bool
+		wantErr bool
+	}{
+		{
+			name:    "not set",
+			wantEnv: "TEST_NODE_MONIKER",
+		},
+		{
+			name:    "set",
+			env:     map[string]string{"TEST_NODE_MONIKER": "moniker"},
+			wantEnv: "TEST_NODE_MONIKER",
+			value:   "moniker",
+			ok:      true,
+		},
+		{
+			name:    "set to empty",
+			env:     map[string]string{"TEST_NODE_MONIKER": ""},
+			wantEnv: "TEST_NODE_MONIKER",
+			value:   "",
+			ok:      true,
+		},
+		{
+			name:    "file with the trailing newline trimmed",
+			env:     map[string]string{"TEST_NODE_MONIKER_FILE": path},
+			wantEnv: "TEST_NODE_MONIKER_FILE",
+			value:   "from file",
+			ok:      true,
+		},
+		{
+			name:    "missing file",
+			env:     map[string]string{"TEST_NODE_MONIKER_FILE": path + ".missing"},
+			wantErr: true,
+		},
+		{
+			name: "both set",
+			env: map[string]string{
+				"TEST_NODE_MONIKER":      "moniker",
+				"TEST_NODE_MONIKER_FILE": path,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+
+			env, value, ok, err := lookupEnv("TEST", "node.moniker")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %t, got %v", tc.wantErr, err)
+			}
+			if err != nil {
+				if _, err = EnvOverrides("TEST", []string{"node.moniker"}); err == nil {
+					t.Error("expected the error to be reported by EnvOverrides")
+				}
+				return
+			}
+			if env != tc.wantEnv || value != tc.value || ok != tc.ok {
+				t.Errorf("expected (%q, %q, %t), got (%q, %q, %t)", tc.wantEnv, tc.value, tc.ok, env, value, ok)
+			}
+		})
+	}
+}